@@ -0,0 +1,554 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// Cluster is one monitored cluster. Its collector keeps polling while another
+// cluster is on screen, so switching back finds the rate history intact.
+type Cluster struct {
+	Name      string
+	collector *Collector
+
+	indexActivities map[string]*IndexActivity
+}
+
+func NewCluster(name string, collector *Collector) *Cluster {
+	return &Cluster{
+		Name:            name,
+		collector:       collector,
+		indexActivities: make(map[string]*IndexActivity),
+	}
+}
+
+// App is the dashboard: its widgets, which panels are toggled on and the
+// clusters it can show. Every field is owned by the tview event loop; the
+// collector goroutines only reach the App through QueueUpdateDraw, so no
+// locking is needed here.
+type App struct {
+	tv    *tview.Application
+	pages *tview.Pages // The dashboard, with a detail screen over it when one is open
+	root  *tview.Flex
+	grid  *tview.Grid
+
+	clusters []*Cluster
+	current  int
+
+	// collector and indexActivities belong to the cluster on screen
+	collector       *Collector
+	indexActivities map[string]*IndexActivity
+
+	clusterBar   *tview.TextView
+	header       *tview.TextView
+	headerHeight int // Lines of the header, which grows for a warning
+	nodesPanel   *tview.Flex
+	rolesPanel   *tview.TextView
+	indicesPanel *tview.Flex
+	metricsPanel *tview.TextView
+
+	// The nodes and indices panels are a title above a table, the indices
+	// panel has the totals below it
+	nodesTitle    *tview.TextView
+	nodesTable    *tview.Table
+	indicesTitle  *tview.TextView
+	indicesTable  *tview.Table
+	indicesFooter *tview.TextView
+
+	// The sort of each table, kept across refreshes
+	nodesSort   tableSort
+	indicesSort tableSort
+
+	// indexFilter selects the listed indices, it is typed into filterInput
+	// after '/'. filterErr is why the text in filterInput is not in effect.
+	filterInput *tview.InputField
+	indexFilter *indexFilter
+	filterErr   error
+
+	// detail is the detail screen of an index or node, open while detailLoad
+	// is set. detailSeq tells the latest load apart from earlier ones still
+	// running, detailFrom has the focus back once it closes.
+	detail     *tview.TextView
+	detailLoad detailLoader
+	detailSeq  int
+	detailFrom tview.Primitive
+
+	showNodes         bool
+	showRoles         bool
+	showIndices       bool
+	showMetrics       bool
+	showHiddenIndices bool
+	rateWindow        time.Duration
+
+	// paused freezes the view, renderNext lets a single refresh through it.
+	// shown is the collector state on screen, which every redraw reuses
+	// while paused; nil until the cluster on screen was first drawn.
+	paused     bool
+	renderNext bool
+	shown      *viewState
+
+	theme Theme
+}
+
+// viewState is the state of a collector as returned by Collector.State
+type viewState struct {
+	snap  *Snapshot
+	stale bool
+	err   error
+}
+
+// AppOptions are the settings the dashboard starts with
+type AppOptions struct {
+	RateWindow    time.Duration
+	Theme         Theme
+	Panels        Panels
+	HiddenIndices bool
+}
+
+// Panels says which of the toggleable panels are visible
+type Panels struct {
+	Nodes   bool
+	Roles   bool
+	Indices bool
+	Metrics bool
+}
+
+// parsePanels reads a comma separated list of panel names such as "nodes,metrics"
+func parsePanels(list string) (Panels, error) {
+	var panels Panels
+	for _, name := range strings.Split(list, ",") {
+		switch strings.TrimSpace(name) {
+		case "":
+		case "nodes":
+			panels.Nodes = true
+		case "roles":
+			panels.Roles = true
+		case "indices":
+			panels.Indices = true
+		case "metrics":
+			panels.Metrics = true
+		default:
+			return Panels{}, fmt.Errorf("unknown panel %q, use nodes, roles, indices or metrics", name)
+		}
+	}
+	return panels, nil
+}
+
+// NewApp builds the dashboard for clusters, which must not be empty. The
+// first one is shown at startup.
+func NewApp(clusters []*Cluster, opts AppOptions) *App {
+	// Widgets copy the style defaults when they are built
+	opts.Theme.applyStyles()
+
+	a := &App{
+		tv:                tview.NewApplication(),
+		clusters:          clusters,
+		collector:         clusters[0].collector,
+		indexActivities:   clusters[0].indexActivities,
+		showNodes:         opts.Panels.Nodes,
+		showRoles:         opts.Panels.Roles,
+		showIndices:       opts.Panels.Indices,
+		showMetrics:       opts.Panels.Metrics,
+		showHiddenIndices: opts.HiddenIndices,
+		rateWindow:        opts.RateWindow,
+		theme:             opts.Theme,
+		nodesSort:         tableSort{column: nodeColumnName},
+		indicesSort:       tableSort{column: indexColumnRate, desc: true},
+		headerHeight:      3,
+	}
+
+	// Update the grid layout to use proportional columns
+	a.grid = tview.NewGrid().
+		SetRows(3, 0, 0).       // Three rows: header, nodes, bottom panels
+		SetColumns(-1, -2, -1). // Three columns for bottom row: roles (1), indices (2), metrics (1)
+		SetBorders(true)
+
+	a.header = tview.NewTextView().
+		SetDynamicColors(true).
+		SetTextAlign(tview.AlignLeft)
+
+	a.nodesTitle = tview.NewTextView().
+		SetDynamicColors(true)
+	a.nodesTable = newTable(opts.Theme)
+	a.nodesPanel = tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(a.nodesTitle, 2, 0, false).
+		AddItem(a.nodesTable, 0, 1, true)
+	a.nodesTable.SetSelectedFunc(func(row, column int) {
+		if id := selectedKey(a.nodesTable); id != "" {
+			a.openNodeDetail(id)
+		}
+	})
+
+	a.rolesPanel = tview.NewTextView().
+		SetDynamicColors(true)
+
+	a.indicesTitle = tview.NewTextView().
+		SetDynamicColors(true)
+	a.indicesTable = newTable(opts.Theme)
+	a.indicesFooter = tview.NewTextView().
+		SetDynamicColors(true)
+	a.filterInput = tview.NewInputField().
+		SetLabel("/").
+		SetFieldBackgroundColor(opts.Theme.Background).
+		SetFieldTextColor(opts.Theme.Text).
+		SetChangedFunc(func(text string) {
+			a.setIndexFilter(text)
+			a.render()
+		}).
+		SetDoneFunc(a.closeFilter)
+	// The filter input only takes a line while it is being edited
+	a.indicesPanel = tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(a.indicesTitle, 2, 0, false).
+		AddItem(a.filterInput, 0, 0, false).
+		AddItem(a.indicesTable, 0, 1, true).
+		AddItem(a.indicesFooter, 4, 0, false)
+	a.indicesTable.SetSelectedFunc(func(row, column int) {
+		if index := selectedKey(a.indicesTable); index != "" {
+			a.openIndexDetail(index)
+		}
+	})
+
+	a.metricsPanel = tview.NewTextView().
+		SetDynamicColors(true)
+
+	a.clusterBar = tview.NewTextView().
+		SetDynamicColors(true)
+
+	a.updateGridLayout()
+
+	// The cluster list sits above the dashboard, only when there is a choice
+	a.root = tview.NewFlex().SetDirection(tview.FlexRow)
+	if len(clusters) > 1 {
+		a.root.AddItem(a.clusterBar, 1, 0, false)
+		a.renderClusterBar()
+	}
+	a.root.AddItem(a.grid, 0, 1, true)
+
+	a.detail = tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true)
+	a.detail.SetBorder(true)
+	a.pages = tview.NewPages().
+		AddPage("dashboard", a.root, true, true).
+		AddPage("detail", a.detail, true, false)
+
+	a.tv.SetInputCapture(a.handleKey)
+	return a
+}
+
+// Run starts polling in the background and blocks until the user quits
+func (a *App) Run() error {
+	// Poll in the background and only hand the rendering to the UI goroutine,
+	// so a slow cluster never blocks keypresses. Clusters that are not on
+	// screen only update their entry in the cluster list.
+	for _, cluster := range a.clusters {
+		cluster := cluster
+		go cluster.collector.Run(func() {
+			a.tv.QueueUpdateDraw(func() {
+				if len(a.clusters) > 1 {
+					a.renderClusterBar()
+				}
+				if cluster == a.clusters[a.current] {
+					a.update()
+				}
+			})
+		})
+	}
+
+	a.tv.SetRoot(a.pages, true).EnableMouse(true)
+	a.focusTable(a.nodesTable)
+	return a.tv.Run()
+}
+
+// update is called after every poll and redraws unless the view is paused
+func (a *App) update() {
+	if a.paused && !a.renderNext {
+		return
+	}
+	a.renderNext = false
+	a.shown = nil
+	a.render()
+}
+
+// render redraws the dashboard from the collector's latest snapshot, or
+// from the one already on screen while paused
+func (a *App) render() {
+	if a.shown == nil || !a.paused {
+		snap, stale, err := a.collector.State()
+		a.shown = &viewState{snap, stale, err}
+	}
+	a.renderDashboard(a.shown.snap, a.shown.stale, a.shown.err)
+}
+
+// handleKey runs the dashboard's shortcuts. Keys it does not handle go on to
+// the focused table, which moves its cursor with the arrows, j/k and g/G.
+func (a *App) handleKey(event *tcell.EventKey) *tcell.EventKey {
+	// Everything typed while editing the filter belongs to it
+	if a.tv.GetFocus() == a.filterInput {
+		return event
+	}
+	if a.detailLoad != nil {
+		return a.handleDetailKey(event)
+	}
+
+	switch event.Key() {
+	case tcell.KeyEsc:
+		a.tv.Stop()
+	case tcell.KeyTab:
+		a.switchCluster(a.current + 1)
+	case tcell.KeyBacktab:
+		a.switchCluster(a.current - 1)
+	case tcell.KeyRune:
+		switch event.Rune() {
+		case 'q':
+			a.tv.Stop()
+		case '2':
+			a.showNodes = !a.showNodes
+			a.updateGridLayout()
+		case '3':
+			a.showRoles = !a.showRoles
+			a.updateGridLayout()
+		case '4':
+			a.showIndices = !a.showIndices
+			a.updateGridLayout()
+		case '5':
+			a.showMetrics = !a.showMetrics
+			a.updateGridLayout()
+		case 'h':
+			a.showHiddenIndices = !a.showHiddenIndices
+			// Redraw from the last snapshot rather than waiting for the next poll
+			a.render()
+		case 'w':
+			a.cycleRateWindow()
+			a.render()
+		case 'p':
+			a.paused = !a.paused
+			for _, cluster := range a.clusters {
+				cluster.collector.SetPaused(a.paused)
+			}
+			a.render()
+		case 'r':
+			// While paused, let the result of this one refresh through
+			a.renderNext = a.paused
+			a.collector.Refresh()
+		case '+':
+			a.collector.Faster()
+			a.render()
+		case '-':
+			a.collector.Slower()
+			a.render()
+		case 'n':
+			a.focusTable(a.nodesTable)
+		case 'i':
+			a.focusTable(a.indicesTable)
+		case '<', '>', 'o':
+			a.changeSort(event.Rune())
+			a.render()
+		case '/':
+			a.openFilter()
+		default:
+			return event
+		}
+	default:
+		return event
+	}
+	return nil
+}
+
+// changeSort moves the sort of the focused table to the previous or next
+// column for '<' and '>', or reverses it for 'o'
+func (a *App) changeSort(key rune) {
+	dir := 1
+	if key == '<' {
+		dir = -1
+	}
+	switch a.tv.GetFocus() {
+	case a.nodesTable:
+		if key == 'o' {
+			a.nodesSort.desc = !a.nodesSort.desc
+		} else {
+			stepSort(&a.nodesSort, nodeColumns, dir)
+		}
+	case a.indicesTable:
+		if key == 'o' {
+			a.indicesSort.desc = !a.indicesSort.desc
+		} else {
+			stepSort(&a.indicesSort, indexColumns, dir)
+		}
+	}
+}
+
+// setIndexFilter filters the indices panel with expr. An invalid filter is
+// reported and the last valid one stays in effect, so that a regex that is
+// still being typed does not flash the whole list.
+func (a *App) setIndexFilter(expr string) {
+	filter, err := parseIndexFilter(expr)
+	a.filterErr = err
+	if err == nil {
+		a.indexFilter = filter
+	}
+}
+
+// openFilter shows the filter input above the indices, with the filter in
+// effect ready to be edited
+func (a *App) openFilter() {
+	if !a.showIndices {
+		a.showIndices = true
+		a.updateGridLayout()
+	}
+	a.indicesPanel.ResizeItem(a.filterInput, 1, 0)
+	a.tv.SetFocus(a.filterInput)
+}
+
+// closeFilter hides the filter input. Enter keeps the filter, Esc clears it.
+func (a *App) closeFilter(key tcell.Key) {
+	if key == tcell.KeyEscape {
+		a.filterInput.SetText("")
+	}
+	// A filter that does not parse goes back to the one in effect
+	if a.filterErr != nil {
+		expr := ""
+		if a.indexFilter != nil {
+			expr = a.indexFilter.expr
+		}
+		a.filterInput.SetText(expr)
+	}
+	a.indicesPanel.ResizeItem(a.filterInput, 0, 0)
+	a.focusTable(a.indicesTable)
+}
+
+// focusTable gives the cursor to table, or to the other table when the
+// panel of this one is hidden
+func (a *App) focusTable(table *tview.Table) {
+	if table == a.nodesTable && !a.showNodes {
+		table = a.indicesTable
+	} else if table == a.indicesTable && !a.showIndices {
+		table = a.nodesTable
+	}
+	if (table == a.nodesTable && !a.showNodes) || (table == a.indicesTable && !a.showIndices) {
+		a.tv.SetFocus(a.grid)
+		return
+	}
+	a.tv.SetFocus(table)
+}
+
+func (a *App) updateGridLayout() {
+	grid := a.grid
+	defer a.keepFocusVisible()
+
+	// Start with clean grid
+	grid.Clear()
+
+	visiblePanels := 0
+	if a.showRoles {
+		visiblePanels++
+	}
+	if a.showIndices {
+		visiblePanels++
+	}
+	if a.showMetrics {
+		visiblePanels++
+	}
+
+	// When only nodes panel is visible, use a single column layout
+	if a.showNodes && visiblePanels == 0 {
+		grid.SetRows(a.headerHeight, 0) // Header and nodes only
+		grid.SetColumns(0)              // Single full-width column
+
+		// Add header and nodes panel
+		grid.AddItem(a.header, 0, 0, 1, 1, 0, 0, false)
+		grid.AddItem(a.nodesPanel, 1, 0, 1, 1, 0, 0, false)
+		return
+	}
+
+	// Rest of the layout logic for when bottom panels are visible
+	if a.showNodes {
+		grid.SetRows(a.headerHeight, 0, 0) // Header, nodes, bottom panels
+	} else {
+		grid.SetRows(a.headerHeight, 0) // Just header and bottom panels
+	}
+
+	// Configure columns based on visible panels
+	switch {
+	case visiblePanels == 3:
+		if a.showRoles {
+			grid.SetColumns(30, -2, -1)
+		}
+	case visiblePanels == 2:
+		if a.showRoles {
+			grid.SetColumns(30, 0)
+		} else {
+			grid.SetColumns(-1, -1)
+		}
+	case visiblePanels == 1:
+		grid.SetColumns(0)
+	}
+
+	// Always show header at top spanning all columns
+	grid.AddItem(a.header, 0, 0, 1, visiblePanels, 0, 0, false)
+
+	// Add nodes panel if visible, spanning all columns
+	if a.showNodes {
+		grid.AddItem(a.nodesPanel, 1, 0, 1, visiblePanels, 0, 0, false)
+	}
+
+	// Add bottom panels in their respective positions
+	row := 1
+	if a.showNodes {
+		row = 2
+	}
+	col := 0
+	if a.showRoles {
+		grid.AddItem(a.rolesPanel, row, col, 1, 1, 0, 0, false)
+		col++
+	}
+	if a.showIndices {
+		grid.AddItem(a.indicesPanel, row, col, 1, 1, 0, 0, false)
+		col++
+	}
+	if a.showMetrics {
+		grid.AddItem(a.metricsPanel, row, col, 1, 1, 0, 0, false)
+	}
+}
+
+// keepFocusVisible hands the cursor to the other table when the panel that
+// has it is hidden, and back once one of them shows again
+func (a *App) keepFocusVisible() {
+	switch a.tv.GetFocus() {
+	case a.nodesTable, a.grid:
+		a.focusTable(a.nodesTable)
+	case a.indicesTable:
+		a.focusTable(a.indicesTable)
+	}
+}
+
+// switchCluster puts the i-th cluster on screen, wrapping around at both ends
+func (a *App) switchCluster(i int) {
+	if len(a.clusters) < 2 {
+		return
+	}
+	a.current = (i + len(a.clusters)) % len(a.clusters)
+	cluster := a.clusters[a.current]
+	a.collector = cluster.collector
+	a.indexActivities = cluster.indexActivities
+	// Nothing of this cluster is on screen yet, paused or not
+	a.shown = nil
+	a.renderClusterBar()
+	a.render()
+}
+
+// cycleRateWindow switches rates to the next window the collector computes
+func (a *App) cycleRateWindow() {
+	windows := a.collector.windows
+	for i, w := range windows {
+		if w == a.rateWindow {
+			a.rateWindow = windows[(i+1)%len(windows)]
+			return
+		}
+	}
+	a.rateWindow = windows[0]
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// AuthOptions collects the credentials given through flags and environment
+type AuthOptions struct {
+	User         string
+	Password     string
+	PasswordFile string
+	APIKey       string
+	APIKeyFile   string
+	Token        string
+	TokenFile    string
+}
+
+// Resolve reads any secret files and picks the authentication scheme. With
+// no credentials at all it falls back to ~/.netrc for host and then to
+// anonymous access (a nil Auth). A user without a password is prompted for
+// one when running in a terminal.
+func (o AuthOptions) Resolve(host string) (Auth, error) {
+	var err error
+	if o.Password, err = secretFromFile(o.Password, o.PasswordFile); err != nil {
+		return nil, err
+	}
+	if o.APIKey, err = secretFromFile(o.APIKey, o.APIKeyFile); err != nil {
+		return nil, err
+	}
+	if o.Token, err = secretFromFile(o.Token, o.TokenFile); err != nil {
+		return nil, err
+	}
+
+	schemes := 0
+	for _, set := range []bool{o.APIKey != "", o.Token != "", o.User != "" || o.Password != ""} {
+		if set {
+			schemes++
+		}
+	}
+	if schemes > 1 {
+		return nil, errors.New("use only one of API key, bearer token or username/password authentication")
+	}
+
+	switch {
+	case o.APIKey != "":
+		return APIKeyAuth{Key: o.APIKey}, nil
+	case o.Token != "":
+		return BearerAuth{Token: o.Token}, nil
+	case o.User != "":
+		if o.Password == "" {
+			if o.Password, err = promptPassword(o.User, host); err != nil {
+				return nil, err
+			}
+		}
+		return BasicAuth{Username: o.User, Password: o.Password}, nil
+	case o.Password != "":
+		return nil, errors.New("a password was given without a username")
+	}
+
+	login, password, err := netrcLookup(host)
+	if err != nil {
+		return nil, err
+	}
+	if login != "" {
+		return BasicAuth{Username: login, Password: password}, nil
+	}
+
+	return nil, nil
+}
+
+// secretFromFile returns the contents of path without the trailing newline,
+// or value when no path is given. Giving both is an error.
+func secretFromFile(value, path string) (string, error) {
+	if path == "" {
+		return value, nil
+	}
+	if value != "" {
+		return "", fmt.Errorf("cannot use both a secret and a secret file (%s)", path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("reading secret file: %w", err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// promptPassword asks for a password on the terminal without echoing it
+func promptPassword(user, host string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return "", fmt.Errorf("no password for user %s and no terminal to prompt for one", user)
+	}
+
+	fmt.Fprintf(os.Stderr, "Password for %s@%s: ", user, host)
+	password, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("reading password: %w", err)
+	}
+	return string(password), nil
+}
+
+// netrcLookup returns the login and password ~/.netrc (or $NETRC) holds for
+// host, falling back to its default entry. A missing file is not an error.
+func netrcLookup(host string) (string, string, error) {
+	path := os.Getenv("NETRC")
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", "", nil
+		}
+		path = filepath.Join(home, ".netrc")
+	}
+
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", "", nil
+	}
+	if err != nil {
+		return "", "", fmt.Errorf("reading netrc: %w", err)
+	}
+	defer f.Close()
+
+	return parseNetrc(bufio.NewScanner(f), host)
+}
+
+func parseNetrc(scanner *bufio.Scanner, host string) (string, string, error) {
+	scanner.Split(bufio.ScanWords)
+
+	var (
+		login, password               string
+		defaultLogin, defaultPassword string
+		inMachine, inDefault, found   bool
+	)
+
+	for scanner.Scan() {
+		switch token := scanner.Text(); token {
+		case "machine":
+			if found {
+				return login, password, nil
+			}
+			if !scanner.Scan() {
+				break
+			}
+			inMachine, inDefault = scanner.Text() == host, false
+			found = inMachine
+		case "default":
+			if found {
+				return login, password, nil
+			}
+			inMachine, inDefault = false, true
+		case "login", "password", "account":
+			if !scanner.Scan() {
+				break
+			}
+			value := scanner.Text()
+			switch {
+			case inMachine && token == "login":
+				login = value
+			case inMachine && token == "password":
+				password = value
+			case inDefault && token == "login":
+				defaultLogin = value
+			case inDefault && token == "password":
+				defaultPassword = value
+			}
+		case "macdef":
+			// Macros run until the next blank line, which ScanWords cannot
+			// see, so stop here rather than misreading them as entries
+			if found {
+				return login, password, nil
+			}
+			return defaultLogin, defaultPassword, scanner.Err()
+		}
+	}
+
+	if found {
+		return login, password, scanner.Err()
+	}
+	return defaultLogin, defaultPassword, scanner.Err()
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseNetrc(t *testing.T) {
+	const netrc = `
+machine es-1.example.com
+  login elastic
+  password secret-1
+machine es-2.example.com login monitor password secret-2 account ops
+default login anonymous password guest
+`
+	tests := []struct {
+		name, netrc, host     string
+		wantLogin, wantSecret string
+	}{
+		{name: "first machine", netrc: netrc, host: "es-1.example.com", wantLogin: "elastic", wantSecret: "secret-1"},
+		{name: "one line", netrc: netrc, host: "es-2.example.com", wantLogin: "monitor", wantSecret: "secret-2"},
+		{name: "default", netrc: netrc, host: "es-3.example.com", wantLogin: "anonymous", wantSecret: "guest"},
+		{name: "no default", netrc: "machine es-1.example.com login elastic password secret-1", host: "es-3.example.com"},
+		{
+			name:       "macros end the file",
+			netrc:      "default login anonymous password guest\nmacdef init\ncd /\n\nmachine es-1.example.com login elastic password secret-1",
+			host:       "es-1.example.com",
+			wantLogin:  "anonymous",
+			wantSecret: "guest",
+		},
+		{name: "empty", host: "es-1.example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			login, password, err := parseNetrc(bufio.NewScanner(strings.NewReader(tt.netrc)), tt.host)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if login != tt.wantLogin || password != tt.wantSecret {
+				t.Errorf("credentials for %s = %q/%q, want %q/%q", tt.host, login, password, tt.wantLogin, tt.wantSecret)
+			}
+		})
+	}
+}
+
+func TestResolveAuth(t *testing.T) {
+	dir := t.TempDir()
+	secretFile := filepath.Join(dir, "secret")
+	if err := os.WriteFile(secretFile, []byte("from-file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	netrc := filepath.Join(dir, "netrc")
+	if err := os.WriteFile(netrc, []byte("machine es.example.com login netrc-user password netrc-secret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		opts    AuthOptions
+		host    string
+		want    Auth
+		wantErr bool
+	}{
+		{name: "basic", opts: AuthOptions{User: "elastic", Password: "secret"}, want: BasicAuth{Username: "elastic", Password: "secret"}},
+		{name: "API key", opts: AuthOptions{APIKey: "key"}, want: APIKeyAuth{Key: "key"}},
+		{name: "token file", opts: AuthOptions{TokenFile: secretFile}, want: BearerAuth{Token: "from-file"}},
+		{name: "password file", opts: AuthOptions{User: "elastic", PasswordFile: secretFile}, want: BasicAuth{Username: "elastic", Password: "from-file"}},
+		{name: "netrc", host: "es.example.com", want: BasicAuth{Username: "netrc-user", Password: "netrc-secret"}},
+		{name: "anonymous", host: "other.example.com", want: nil},
+		{name: "netrc not used with credentials", opts: AuthOptions{APIKey: "key"}, host: "es.example.com", want: APIKeyAuth{Key: "key"}},
+		{name: "two schemes", opts: AuthOptions{APIKey: "key", Token: "token"}, wantErr: true},
+		{name: "secret and its file", opts: AuthOptions{APIKey: "key", APIKeyFile: secretFile}, wantErr: true},
+		{name: "password without a user", opts: AuthOptions{Password: "secret"}, wantErr: true},
+		{name: "missing file", opts: AuthOptions{TokenFile: filepath.Join(dir, "missing")}, wantErr: true},
+	}
+
+	t.Setenv("NETRC", netrc)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.opts.Resolve(tt.host)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Resolve error = %v, want an error: %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Resolve = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
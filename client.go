@@ -0,0 +1,287 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Paths of every endpoint the dashboard polls, also used as keys for Snapshot.Errors
+const (
+	endpointRoot          = "/"
+	endpointClusterStats  = "/_cluster/stats"
+	endpointNodesInfo     = "/_nodes"
+	endpointIndices       = "/_cat/indices?format=json"
+	endpointClusterHealth = "/_cluster/health"
+	endpointNodesStats    = "/_nodes/stats"
+	endpointIndexStats    = "/_stats"
+	endpointDataStreams   = "/_data_stream"
+	endpointNodesHTTP     = "/_nodes/http"
+)
+
+// Auth adds credentials to an outgoing request
+type Auth interface {
+	Apply(req *http.Request)
+}
+
+// BasicAuth authenticates with a username and password
+type BasicAuth struct {
+	Username string
+	Password string
+}
+
+func (a BasicAuth) Apply(req *http.Request) {
+	req.SetBasicAuth(a.Username, a.Password)
+}
+
+// APIKeyAuth authenticates with an encoded Elasticsearch API key
+type APIKeyAuth struct {
+	Key string
+}
+
+func (a APIKeyAuth) Apply(req *http.Request) {
+	req.Header.Set("Authorization", fmt.Sprintf("ApiKey %s", a.Key))
+}
+
+// BearerAuth authenticates with a bearer token, such as a service account
+// token or an OAuth2 access token
+type BearerAuth struct {
+	Token string
+}
+
+func (a BearerAuth) Apply(req *http.Request) {
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", a.Token))
+}
+
+// ClientConfig holds everything needed to build a Client
+type ClientConfig struct {
+	URLs      []string          // Seed URLs of the cluster, e.g. https://localhost:9200
+	Sniff     bool              // Discover the other nodes of the cluster through the seeds
+	Auth      Auth              // Credentials, nil for none
+	Timeout   time.Duration     // Timeout for a single request, 0 for none
+	Transport http.RoundTripper // Transport to use, nil for http.DefaultTransport
+
+	// OnRequest is called right before a request is sent
+	OnRequest func(req *http.Request)
+	// OnResponse is called with the raw body of every response, whatever its status
+	OnResponse func(req *http.Request, resp *http.Response, body []byte)
+}
+
+// Client talks to the Elasticsearch REST API, failing over between the nodes
+// of its pool when one stops answering
+type Client struct {
+	pool       *nodePool
+	sniff      bool
+	auth       Auth
+	httpClient *http.Client
+	onRequest  func(req *http.Request)
+	onResponse func(req *http.Request, resp *http.Response, body []byte)
+}
+
+// APIError is returned when the cluster answers with a non-200 status
+type APIError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("API request failed with status %d: %s", e.StatusCode, e.Body)
+}
+
+// NewClient returns a client for the cluster at cfg.URLs, which must name at
+// least one node
+func NewClient(cfg ClientConfig) (*Client, error) {
+	if len(cfg.URLs) == 0 {
+		return nil, errors.New("no cluster URL given")
+	}
+	return &Client{
+		pool:  newNodePool(cfg.URLs),
+		sniff: cfg.Sniff,
+		auth:  cfg.Auth,
+		httpClient: &http.Client{
+			Transport: cfg.Transport,
+			Timeout:   cfg.Timeout,
+		},
+		onRequest:  cfg.OnRequest,
+		onResponse: cfg.OnResponse,
+	}, nil
+}
+
+// Get performs a GET request against path and decodes the JSON response into
+// target. A node that cannot be reached is taken out of rotation and the
+// request is retried on the next one; an error response is returned as is,
+// since any other node would most likely answer the same.
+func (c *Client) Get(ctx context.Context, path string, target interface{}) error {
+	tried := make(map[*poolNode]bool)
+	var err error
+	for {
+		n := c.pool.pick(tried)
+		if n == nil {
+			if err == nil {
+				err = errors.New("no nodes to send the request to")
+			}
+			return err
+		}
+		tried[n] = true
+
+		var body []byte
+		body, err = c.get(ctx, n.url, path)
+		var apiErr *APIError
+		if err != nil && !errors.As(err, &apiErr) {
+			// Running out of time is not the node's fault
+			if ctx.Err() != nil {
+				return err
+			}
+			c.pool.markDead(n)
+			continue
+		}
+
+		c.pool.markAlive(n)
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal(body, target)
+	}
+}
+
+// get sends a single GET request to the node at baseURL and returns the body
+// of its 200 response
+func (c *Client) get(ctx context.Context, baseURL, path string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", baseURL+path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.auth != nil {
+		c.auth.Apply(req)
+	}
+
+	if c.onRequest != nil {
+		c.onRequest(req)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.onResponse != nil {
+		c.onResponse(req, resp, body)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &APIError{StatusCode: resp.StatusCode, Body: strings.TrimSpace(string(body))}
+	}
+
+	return body, nil
+}
+
+// Current returns the URL of the node requests are currently sent to
+func (c *Client) Current() string {
+	return c.pool.Current()
+}
+
+// SniffIfDue refreshes the node list from /_nodes/http when sniffing is
+// enabled and the list was not refreshed recently. A failed sniff leaves the
+// node list as it was.
+func (c *Client) SniffIfDue(ctx context.Context) error {
+	if !c.sniff || !c.pool.claimSniff() {
+		return nil
+	}
+
+	var v NodesHTTP
+	if err := c.Get(ctx, endpointNodesHTTP, &v); err != nil {
+		return err
+	}
+
+	// Sniffed nodes are reached the same way as the seeds
+	scheme := "http"
+	if strings.HasPrefix(c.pool.seeds[0], "https://") {
+		scheme = "https"
+	}
+
+	var urls []string
+	for _, node := range v.Nodes {
+		if addr := publishURL(node.HTTP.PublishAddress); addr != "" {
+			urls = append(urls, scheme+"://"+addr)
+		}
+	}
+	sort.Strings(urls)
+	c.pool.replace(urls)
+	return nil
+}
+
+// publishURL turns a publish address into host:port. Addresses come either
+// as ip:port or, when the node has a hostname, as hostname/ip:port, in which
+// case the hostname is used so certificates issued for it still verify.
+func publishURL(address string) string {
+	hostname, addr, found := strings.Cut(address, "/")
+	if !found {
+		return address
+	}
+	i := strings.LastIndex(addr, ":")
+	if hostname == "" || i < 0 {
+		return addr
+	}
+	return hostname + addr[i:]
+}
+
+func (c *Client) Root(ctx context.Context) (RootInfo, error) {
+	var v RootInfo
+	err := c.Get(ctx, endpointRoot, &v)
+	return v, err
+}
+
+func (c *Client) ClusterStats(ctx context.Context) (ClusterStats, error) {
+	var v ClusterStats
+	err := c.Get(ctx, endpointClusterStats, &v)
+	return v, err
+}
+
+func (c *Client) NodesInfo(ctx context.Context) (NodesInfo, error) {
+	var v NodesInfo
+	err := c.Get(ctx, endpointNodesInfo, &v)
+	return v, err
+}
+
+func (c *Client) CatIndices(ctx context.Context) (IndexStats, error) {
+	var v IndexStats
+	err := c.Get(ctx, endpointIndices, &v)
+	return v, err
+}
+
+func (c *Client) ClusterHealth(ctx context.Context) (ClusterHealth, error) {
+	var v ClusterHealth
+	err := c.Get(ctx, endpointClusterHealth, &v)
+	return v, err
+}
+
+func (c *Client) NodesStats(ctx context.Context) (NodesStats, error) {
+	var v NodesStats
+	err := c.Get(ctx, endpointNodesStats, &v)
+	return v, err
+}
+
+func (c *Client) IndexWriteStats(ctx context.Context) (IndexWriteStats, error) {
+	var v IndexWriteStats
+	err := c.Get(ctx, endpointIndexStats, &v)
+	return v, err
+}
+
+func (c *Client) DataStreams(ctx context.Context) (DataStreamResponse, error) {
+	var v DataStreamResponse
+	err := c.Get(ctx, endpointDataStreams, &v)
+	return v, err
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNewClientWithoutURLs(t *testing.T) {
+	if _, err := NewClient(ClientConfig{}); err == nil {
+		t.Error("a client without URLs must be rejected")
+	}
+}
+
+func TestClientGet(t *testing.T) {
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"cluster_name":"prod-logging"}`))
+	}))
+	t.Cleanup(up.Close)
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(`{"status":503}`))
+	}))
+	t.Cleanup(failing.Close)
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+
+	tests := []struct {
+		name       string
+		urls       []string
+		wantErr    bool
+		wantStatus int // Of the APIError returned
+		wantServed string
+	}{
+		{name: "up", urls: []string{up.URL}, wantServed: up.URL},
+		{name: "fails over", urls: []string{down.URL, up.URL}, wantServed: up.URL},
+		{name: "all down", urls: []string{down.URL}, wantErr: true},
+		{name: "error answers are not retried", urls: []string{failing.URL, up.URL}, wantErr: true, wantStatus: http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewClient(ClientConfig{URLs: tt.urls})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var v ClusterStats
+			err = client.Get(context.Background(), endpointClusterStats, &v)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Get error = %v, want an error: %v", err, tt.wantErr)
+			}
+			var apiErr *APIError
+			if tt.wantStatus != 0 && (!errors.As(err, &apiErr) || apiErr.StatusCode != tt.wantStatus) {
+				t.Errorf("Get error = %v, want status %d", err, tt.wantStatus)
+			}
+			if tt.wantServed != "" {
+				if v.ClusterName != "prod-logging" {
+					t.Errorf("decoded cluster name %q, want prod-logging", v.ClusterName)
+				}
+				if got := client.Current(); got != tt.wantServed {
+					t.Errorf("served by %s, want %s", got, tt.wantServed)
+				}
+			}
+		})
+	}
+}
+
+func TestPublishURL(t *testing.T) {
+	tests := []struct {
+		address, want string
+	}{
+		{"10.0.0.1:9200", "10.0.0.1:9200"},
+		{"es-1.internal/10.0.0.1:9200", "es-1.internal:9200"},
+		{"/10.0.0.1:9200", "10.0.0.1:9200"},
+		{"[::1]:9200", "[::1]:9200"},
+		{"es-1.internal/[::1]:9200", "es-1.internal:9200"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := publishURL(tt.address); got != tt.want {
+			t.Errorf("publishURL(%q) = %q, want %q", tt.address, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,411 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// pollTimeout is the deadline shared by all requests of a single poll
+const pollTimeout = 10 * time.Second
+
+// Snapshot holds everything fetched from the cluster during a single poll.
+// It is never modified once collect returns, so it can be handed to the UI
+// goroutine without any further locking.
+type Snapshot struct {
+	ClusterStats    ClusterStats
+	NodesInfo       NodesInfo
+	IndicesStats    IndexStats
+	ClusterHealth   ClusterHealth
+	NodesStats      NodesStats
+	IndexWriteStats IndexWriteStats
+	DataStreams     DataStreamResponse
+	LatestVersion   string    // "" while unknown
+	VersionChecked  bool      // Whether the latest version is looked up at all
+	PolledAt        time.Time // When the poll started, which names it in a recording
+	FetchedAt       time.Time
+	ServedBy        string // URL of the node that served the requests, or the position in a replay
+	Distribution    Distribution
+
+	// Rates holds the rates derived from this and earlier polls, per window
+	Rates map[time.Duration]Rates
+
+	// History holds the recent values of every sparkline metric, oldest first
+	History map[string][]float64
+
+	// Errors holds the failure of every endpoint that did not answer in time,
+	// keyed by endpoint path. The matching fields above are left zero.
+	Errors map[string]error
+}
+
+// Failed reports whether the given endpoint could not be fetched
+func (s *Snapshot) Failed(path string) bool {
+	return s.Errors[path] != nil
+}
+
+// endpoint describes a single request made during a poll
+type endpoint struct {
+	path  string
+	fetch func(ctx context.Context, client *Client) (apply func(*Snapshot), err error)
+
+	// supported reports whether the distribution has this endpoint, nil if all do
+	supported func(Distribution) bool
+}
+
+// fetchInto builds an endpoint that fetches a T with the given client method
+// and, on success, stores it in the snapshot with set
+func fetchInto[T any](path string, get func(*Client, context.Context) (T, error), set func(*Snapshot, T)) endpoint {
+	return endpoint{
+		path: path,
+		fetch: func(ctx context.Context, client *Client) (func(*Snapshot), error) {
+			v, err := get(client, ctx)
+			if err != nil {
+				return nil, err
+			}
+			return func(s *Snapshot) { set(s, v) }, nil
+		},
+	}
+}
+
+var endpoints = []endpoint{
+	fetchInto(endpointClusterStats, (*Client).ClusterStats, func(s *Snapshot, v ClusterStats) { s.ClusterStats = v }),
+	fetchInto(endpointNodesInfo, (*Client).NodesInfo, func(s *Snapshot, v NodesInfo) { s.NodesInfo = v }),
+	fetchInto(endpointIndices, (*Client).CatIndices, func(s *Snapshot, v IndexStats) { s.IndicesStats = v }),
+	fetchInto(endpointClusterHealth, (*Client).ClusterHealth, func(s *Snapshot, v ClusterHealth) { s.ClusterHealth = v }),
+	fetchInto(endpointNodesStats, (*Client).NodesStats, func(s *Snapshot, v NodesStats) { s.NodesStats = v }),
+	fetchInto(endpointIndexStats, (*Client).IndexWriteStats, func(s *Snapshot, v IndexWriteStats) { s.IndexWriteStats = v }),
+	withSupport(Distribution.hasDataStreams,
+		fetchInto(endpointDataStreams, (*Client).DataStreams, func(s *Snapshot, v DataStreamResponse) { s.DataStreams = v })),
+}
+
+// withSupport restricts ep to the distributions for which supported is true
+func withSupport(supported func(Distribution) bool, ep endpoint) endpoint {
+	ep.supported = supported
+	return ep
+}
+
+// collect fetches every endpoint in parallel and returns whatever arrived
+// before the deadline. An error is only returned when nothing could be fetched.
+func (c *Collector) collect(timeout time.Duration) (*Snapshot, error) {
+	type result struct {
+		path  string
+		apply func(*Snapshot)
+		err   error
+	}
+
+	// A replay steps to its next recorded poll and reports that poll's time
+	polledAt := time.Now()
+	if c.replay != nil {
+		var err error
+		if polledAt, err = c.replay.Next(); err != nil {
+			return nil, err
+		}
+	}
+
+	// Requests still running at the deadline are cancelled through the context
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	ctx = withPollTime(ctx, polledAt)
+
+	// A failed sniff is not worth an error of its own: the known nodes are
+	// kept and the requests below report any real trouble
+	c.client.SniffIfDue(ctx)
+
+	// The distribution decides which endpoints exist. Until the root endpoint
+	// answers a recent Elasticsearch is assumed, and it is asked again next poll.
+	if c.dist == nil {
+		if root, err := c.client.Root(ctx); err == nil {
+			dist := distributionOf(root)
+			c.dist = &dist
+		}
+	}
+	dist := defaultDistribution
+	if c.dist != nil {
+		dist = *c.dist
+	}
+
+	var active []endpoint
+	for _, ep := range endpoints {
+		if ep.supported == nil || ep.supported(dist) {
+			active = append(active, ep)
+		}
+	}
+
+	results := make(chan result, len(active))
+	for _, ep := range active {
+		go func(ep endpoint) {
+			apply, err := ep.fetch(ctx, c.client)
+			results <- result{path: ep.path, apply: apply, err: err}
+		}(ep)
+	}
+
+	snap := &Snapshot{Distribution: dist, PolledAt: polledAt, Errors: make(map[string]error)}
+	for range active {
+		r := <-results
+		if r.err != nil {
+			snap.Errors[r.path] = r.err
+			continue
+		}
+		r.apply(snap)
+	}
+
+	if len(snap.Errors) == len(active) {
+		return nil, snap.Errors[endpointClusterStats]
+	}
+
+	if !snap.Failed(endpointNodesInfo) {
+		normalizeRoles(&snap.NodesInfo)
+	}
+
+	if c.versions != nil {
+		feed := c.releaseFeed
+		if feed == "" {
+			feed = dist.releasesURL()
+		}
+		snap.LatestVersion = c.versions.Latest(feed, c.releaseClient)
+		snap.VersionChecked = true
+	}
+	snap.FetchedAt = time.Now()
+	snap.ServedBy = c.client.Current()
+	if c.replay != nil {
+		snap.FetchedAt = polledAt
+		snap.ServedBy = c.replay.Position()
+	}
+
+	c.rates.observeSnapshot(snap, snap.FetchedAt)
+	snap.Rates = make(map[time.Duration]Rates)
+	for _, window := range c.windows {
+		snap.Rates[window] = c.rates.ratesFor(snap, window)
+	}
+
+	c.history.observeSnapshot(snap, snap.Rates[c.windows[0]])
+	snap.History = c.history.Copy()
+	return snap, nil
+}
+
+// intervalSteps are the refresh intervals '+' and '-' step through
+var intervalSteps = []time.Duration{
+	1 * time.Second,
+	2 * time.Second,
+	5 * time.Second,
+	10 * time.Second,
+	15 * time.Second,
+	30 * time.Second,
+	time.Minute,
+}
+
+// minInterval is the fastest refresh allowed, which the rate engine is sized for
+const minInterval = time.Second
+
+// Collector polls the cluster in the background and keeps the most recent
+// successful snapshot around for the UI to render.
+type Collector struct {
+	client  *Client
+	windows []time.Duration
+
+	// versions looks up the latest release at releaseFeed, or at the feed of
+	// the distribution when it is empty, through releaseClient. nil disables
+	// the check.
+	versions      *versionChecker
+	releaseFeed   string
+	releaseClient *http.Client
+
+	// replay, when set, is the recording the client is served from
+	replay *Replay
+
+	// Only touched by the polling goroutine
+	dist    *Distribution // Detected from the root endpoint, nil until then
+	rates   *RateEngine
+	history *History
+
+	// wake interrupts the wait between polls, refresh asks for a poll right away
+	wake    chan struct{}
+	refresh chan struct{}
+
+	mu       sync.Mutex
+	interval time.Duration
+	paused   bool
+	snapshot *Snapshot
+	err      error
+	stale    bool
+}
+
+// NewCollector builds a collector polling every interval and deriving rates
+// over each of windows, which must not be empty
+func NewCollector(client *Client, interval time.Duration, windows []time.Duration) *Collector {
+	return &Collector{
+		client:   client,
+		interval: interval,
+		windows:  windows,
+		rates:    NewRateEngine(windows[len(windows)-1]),
+		history:  NewHistory(),
+		wake:     make(chan struct{}, 1),
+		refresh:  make(chan struct{}, 1),
+	}
+}
+
+// CheckVersions enables the latest release check through versions, at
+// feed or, when feed is empty, at the feed of the cluster's distribution.
+// The feed is requested through client.
+func (c *Collector) CheckVersions(versions *versionChecker, feed string, client *http.Client) {
+	c.versions = versions
+	c.releaseFeed = feed
+	c.releaseClient = client
+}
+
+// Replay has every poll step through r, which must be the transport of the
+// collector's client
+func (c *Collector) Replay(r *Replay) {
+	c.replay = r
+}
+
+// Run polls forever, calling notify whenever there is something new to render.
+// notify is called from the collector goroutine, so it should only schedule
+// the actual drawing (e.g. via QueueUpdateDraw).
+func (c *Collector) Run(notify func()) {
+	for {
+		polledAt := time.Now()
+		c.poll(notify)
+		c.wait(polledAt)
+	}
+}
+
+// wait blocks until the interval since polledAt has passed or a refresh is
+// requested. A changed interval or pause is picked up without waiting out
+// the old one.
+func (c *Collector) wait(polledAt time.Time) {
+	for {
+		// A paused replay only moves on when asked to
+		var timer *time.Timer
+		var expired <-chan time.Time
+		if !c.held() {
+			remaining := c.Interval() - time.Since(polledAt)
+			if remaining <= 0 {
+				return
+			}
+			timer = time.NewTimer(remaining)
+			expired = timer.C
+		}
+
+		select {
+		case <-expired:
+			return
+		case <-c.refresh:
+			if timer != nil {
+				timer.Stop()
+			}
+			return
+		case <-c.wake:
+			if timer != nil {
+				timer.Stop()
+			}
+		}
+	}
+}
+
+// SetPaused pauses or resumes polling a replay: while paused it only steps
+// to the next recorded poll on Refresh. A live cluster keeps being polled,
+// so its rates stay accurate while the view is paused.
+func (c *Collector) SetPaused(paused bool) {
+	c.mu.Lock()
+	c.paused = paused
+	c.mu.Unlock()
+
+	select {
+	case c.wake <- struct{}{}:
+	default:
+	}
+}
+
+// held reports whether polls wait for a refresh
+func (c *Collector) held() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.paused && c.replay != nil
+}
+
+// Refresh asks for a poll right away instead of at the next interval
+func (c *Collector) Refresh() {
+	select {
+	case c.refresh <- struct{}{}:
+	default:
+	}
+}
+
+func (c *Collector) Interval() time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.interval
+}
+
+func (c *Collector) SetInterval(interval time.Duration) {
+	if interval < minInterval {
+		interval = minInterval
+	}
+
+	c.mu.Lock()
+	c.interval = interval
+	c.mu.Unlock()
+
+	select {
+	case c.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Faster switches to the next shorter step in intervalSteps
+func (c *Collector) Faster() {
+	current := c.Interval()
+	for i := len(intervalSteps) - 1; i >= 0; i-- {
+		if intervalSteps[i] < current {
+			c.SetInterval(intervalSteps[i])
+			return
+		}
+	}
+}
+
+// Slower switches to the next longer step in intervalSteps
+func (c *Collector) Slower() {
+	current := c.Interval()
+	for _, step := range intervalSteps {
+		if step > current {
+			c.SetInterval(step)
+			return
+		}
+	}
+}
+
+func (c *Collector) poll(notify func()) {
+	// If the cluster is slow to answer, flag the data on screen as stale
+	// instead of silently showing numbers from an old poll
+	watchdog := time.AfterFunc(c.Interval(), func() {
+		c.mu.Lock()
+		c.stale = true
+		c.mu.Unlock()
+		notify()
+	})
+
+	snap, err := c.collect(pollTimeout)
+	watchdog.Stop()
+
+	c.mu.Lock()
+	c.err = err
+	if err == nil {
+		c.snapshot = snap
+		c.stale = false
+	} else {
+		c.stale = true
+	}
+	c.mu.Unlock()
+
+	notify()
+}
+
+// State returns the last good snapshot (nil before the first successful poll),
+// whether it is out of date and the error of the most recent poll.
+func (c *Collector) State() (*Snapshot, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.snapshot, c.stale, c.err
+}
@@ -0,0 +1,114 @@
+package main
+
+// Distributions elastop knows how to talk to
+const (
+	distElasticsearch = "elasticsearch"
+	distOpenSearch    = "opensearch"
+)
+
+// RootInfo is the response of the root endpoint, which every version of both
+// distributions answers the same way
+type RootInfo struct {
+	Name        string `json:"name"`
+	ClusterName string `json:"cluster_name"`
+	Version     struct {
+		Number       string `json:"number"`
+		Distribution string `json:"distribution"` // Only set by OpenSearch
+		BuildFlavor  string `json:"build_flavor"`
+	} `json:"version"`
+}
+
+// Distribution is the search engine behind the cluster and its version
+type Distribution struct {
+	Name    string
+	Version string
+}
+
+// defaultDistribution is assumed until the root endpoint has answered
+var defaultDistribution = Distribution{Name: distElasticsearch}
+
+func distributionOf(root RootInfo) Distribution {
+	name := distElasticsearch
+	if root.Version.Distribution == distOpenSearch {
+		name = distOpenSearch
+	}
+	return Distribution{Name: name, Version: root.Version.Number}
+}
+
+func (d Distribution) String() string {
+	name := "Elasticsearch"
+	if d.Name == distOpenSearch {
+		name = "OpenSearch"
+	}
+	if d.Version == "" {
+		return name
+	}
+	return name + " " + d.Version
+}
+
+// atLeast reports whether the version is major.minor or newer. An unknown
+// version counts as new, since that is what elastop was written against.
+func (d Distribution) atLeast(major, minor int) bool {
+	v, ok := parseSemver(d.Version)
+	if !ok {
+		return true
+	}
+	return v.compare(semver{parts: []int{major, minor}}) >= 0
+}
+
+// hasDataStreams reports whether the cluster has the data stream API, which
+// came with Elasticsearch 7.9 and has been in OpenSearch from the start
+func (d Distribution) hasDataStreams() bool {
+	if d.Name == distOpenSearch {
+		return true
+	}
+	return d.atLeast(7, 9)
+}
+
+// releasesURL is where the latest release of the distribution is looked up
+func (d Distribution) releasesURL() string {
+	if d.Name == distOpenSearch {
+		return "https://api.github.com/repos/opensearch-project/OpenSearch/releases/latest"
+	}
+	return "https://api.github.com/repos/elastic/elasticsearch/releases/latest"
+}
+
+// roleAliases maps role names of other distributions and versions to the
+// Elasticsearch 8 names the dashboard uses
+var roleAliases = map[string]string{
+	"cluster_manager": "master", // OpenSearch 2.x
+}
+
+// normalizeRoles rewrites the node roles into the Elasticsearch 8 format:
+// OpenSearch's cluster_manager becomes master, machine learning nodes of
+// Elasticsearch 6.x and early 7.x (which only announce ML through the
+// ml.enabled attribute) get the ml role, and nodes without any role are
+// marked coordinating only, as newer versions do.
+func normalizeRoles(info *NodesInfo) {
+	for id, node := range info.Nodes {
+		seen := make(map[string]bool)
+		roles := make([]string, 0, len(node.Roles))
+		add := func(role string) {
+			if !seen[role] {
+				seen[role] = true
+				roles = append(roles, role)
+			}
+		}
+
+		for _, role := range node.Roles {
+			if alias, ok := roleAliases[role]; ok {
+				role = alias
+			}
+			add(role)
+		}
+		if node.Attributes["ml.enabled"] == "true" {
+			add("ml")
+		}
+		if len(roles) == 0 {
+			add("coordinating_only")
+		}
+
+		node.Roles = roles
+		info.Nodes[id] = node
+	}
+}
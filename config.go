@@ -0,0 +1,620 @@
+package main
+
+import (
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the config file: named cluster profiles and the one to use when
+// -profile is not given
+type Config struct {
+	Default  string             `yaml:"default"`
+	Profiles map[string]Profile `yaml:"profiles"`
+}
+
+// Profile holds the settings of one cluster. Every field mirrors a command
+// line flag, and flags given explicitly win over the profile.
+type Profile struct {
+	URL          string        `yaml:"url"`
+	URLs         []string      `yaml:"urls"`
+	CloudID      string        `yaml:"cloud-id"`
+	Sniff        *bool         `yaml:"sniff"`
+	Proxy        string        `yaml:"proxy"`
+	NoVersion    *bool         `yaml:"no-version-check"`
+	ReleaseFeed  string        `yaml:"release-feed"`
+	User         string        `yaml:"user"`
+	Password     string        `yaml:"password"`
+	PasswordFile string        `yaml:"password-file"`
+	APIKey       string        `yaml:"apikey"`
+	APIKeyFile   string        `yaml:"apikey-file"`
+	Token        string        `yaml:"token"`
+	TokenFile    string        `yaml:"token-file"`
+	Timeout      time.Duration `yaml:"timeout"`
+	Interval     time.Duration `yaml:"interval"`
+	RateWindow   time.Duration `yaml:"rate-window"`
+	Theme        string        `yaml:"theme"`
+	Panels       []string      `yaml:"panels"`
+	Hidden       *bool         `yaml:"hidden-indices"`
+
+	TLS struct {
+		CACert     string `yaml:"cacert"`
+		Cert       string `yaml:"cert"`
+		Key        string `yaml:"key"`
+		ServerName string `yaml:"server-name"`
+		MinVersion string `yaml:"min-version"`
+		Insecure   *bool  `yaml:"insecure"`
+	} `yaml:"tls"`
+}
+
+// defaultConfigPath returns $ELASTOP_CONFIG, or config.yaml in the user's
+// config directory (~/.config/elastop on Linux)
+func defaultConfigPath() string {
+	if path := os.Getenv("ELASTOP_CONFIG"); path != "" {
+		return path
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "elastop", "config.yaml")
+}
+
+// LoadConfig reads the config file at path. A missing file is only an error
+// when required is set, i.e. the path was given explicitly.
+func LoadConfig(path string, required bool) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && !required {
+		return &Config{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading config: %w", err)
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parsing config %s: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+// Profile returns the named profile. An empty name means no profile.
+func (c *Config) Profile(name string) (*Profile, error) {
+	if name == "" {
+		return nil, nil
+	}
+
+	profile, ok := c.Profiles[name]
+	if !ok {
+		names := make([]string, 0, len(c.Profiles))
+		for name := range c.Profiles {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("no profile %q in config, available: %s", name, strings.Join(names, ", "))
+	}
+	return &profile, nil
+}
+
+// flagValues returns the profile as flag values, leaving out unset fields
+func (p *Profile) flagValues() map[string]string {
+	values := make(map[string]string)
+	setString := func(name, value string) {
+		if value != "" {
+			values[name] = value
+		}
+	}
+	setDuration := func(name string, value time.Duration) {
+		if value != 0 {
+			values[name] = value.String()
+		}
+	}
+	setBool := func(name string, value *bool) {
+		if value != nil {
+			values[name] = strconv.FormatBool(*value)
+		}
+	}
+
+	var urls []string
+	for _, u := range append([]string{p.URL}, p.URLs...) {
+		if u != "" {
+			urls = append(urls, u)
+		}
+	}
+	setString("url", strings.Join(urls, ","))
+	setString("cloud-id", p.CloudID)
+	setBool("sniff", p.Sniff)
+	setString("proxy", p.Proxy)
+	setBool("no-version-check", p.NoVersion)
+	setString("release-feed", p.ReleaseFeed)
+
+	setString("user", p.User)
+	setString("password", p.Password)
+	setString("password-file", expandHome(p.PasswordFile))
+	setString("apikey", p.APIKey)
+	setString("apikey-file", expandHome(p.APIKeyFile))
+	setString("token", p.Token)
+	setString("token-file", expandHome(p.TokenFile))
+	setDuration("timeout", p.Timeout)
+	setDuration("interval", p.Interval)
+	setDuration("rate-window", p.RateWindow)
+	setString("theme", p.Theme)
+	setString("panels", strings.Join(p.Panels, ","))
+	setBool("hidden-indices", p.Hidden)
+	setString("cacert", expandHome(p.TLS.CACert))
+	setString("cert", expandHome(p.TLS.Cert))
+	setString("key", expandHome(p.TLS.Key))
+	setString("server-name", p.TLS.ServerName)
+	setString("tls-min-version", p.TLS.MinVersion)
+	setBool("insecure", p.TLS.Insecure)
+
+	return values
+}
+
+// Settings are the settings of one cluster, from the command line and the
+// profile it was started with
+type Settings struct {
+	Name           string // Profile name, or the host without a profile
+	Host           string
+	Port           int
+	URL            string
+	CloudID        string
+	Proxy          string
+	NoVersionCheck bool
+	ReleaseFeed    string
+	Sniff          bool
+	Timeout        time.Duration
+	Interval       time.Duration
+	RateWindow     time.Duration
+	Config         string
+	Profile        string
+	Theme          string
+	Panels         string
+	HiddenIndices  bool
+	Once           bool
+	Format         string
+	Listen         string
+	Record         string
+	Replay         string
+	Auth           AuthOptions
+	TLS            TLSOptions
+}
+
+// registerFlags defines every command line flag on fs, writing into s
+func registerFlags(fs *flag.FlagSet, s *Settings) {
+	fs.StringVar(&s.Host, "host", "http://localhost", "Elasticsearch host URL (e.g., http://localhost or https://example.com), or a comma separated list of seeds to fail over between")
+	fs.IntVar(&s.Port, "port", 9200, "Elasticsearch port, for hosts given without one")
+	fs.StringVar(&s.URL, "url", os.Getenv("ES_URL"), "Full Elasticsearch URL including any path prefix (e.g. https://gw.example.com/es), or a comma separated list; overrides -host and -port")
+	fs.StringVar(&s.CloudID, "cloud-id", os.Getenv("ES_CLOUD_ID"), "Elastic Cloud ID of the deployment; overrides -url, -host and -port")
+	fs.StringVar(&s.Proxy, "proxy", "", "Proxy URL (http, https or socks5) (default from $HTTPS_PROXY / $HTTP_PROXY)")
+	fs.BoolVar(&s.NoVersionCheck, "no-version-check", false, "Do not look up the latest release, e.g. in air-gapped environments")
+	fs.StringVar(&s.ReleaseFeed, "release-feed", "", "URL to look the latest release up at, a GitHub latest release document or plain text (default: GitHub releases of the cluster's distribution)")
+	fs.BoolVar(&s.Sniff, "sniff", false, "Discover the other nodes of the cluster through /_nodes/http and fail over to them")
+	fs.DurationVar(&s.Timeout, "timeout", 10*time.Second, "Timeout for a single Elasticsearch request")
+	fs.DurationVar(&s.Interval, "interval", durationFromEnv("ES_INTERVAL", 5*time.Second), "Refresh interval (change with '+'/'-')")
+	fs.DurationVar(&s.RateWindow, "rate-window", time.Minute, "Window rates are averaged over at startup (cycle with 'w')")
+	fs.StringVar(&s.Config, "config", "", "Config file with cluster profiles (default $ELASTOP_CONFIG or ~/.config/elastop/config.yaml)")
+	fs.StringVar(&s.Profile, "profile", os.Getenv("ELASTOP_PROFILE"), "Comma separated profiles from the config file to monitor, or 'all' (default: the file's default profile)")
+	fs.StringVar(&s.Theme, "theme", "default", "Color theme: default, light or mono")
+	fs.StringVar(&s.Panels, "panels", "nodes,roles,indices,metrics", "Panels shown at startup (toggle with 2-5)")
+	fs.BoolVar(&s.HiddenIndices, "hidden-indices", false, "Show hidden indices at startup (toggle with 'h')")
+	fs.BoolVar(&s.Once, "once", false, "Collect once, print the result and exit instead of starting the dashboard")
+	fs.StringVar(&s.Format, "format", "json", "Output format of -once (json)")
+	fs.StringVar(&s.Listen, "listen", ":9114", "Address 'elastop serve' serves Prometheus metrics on")
+	fs.StringVar(&s.Record, "record", "", "Directory to record every response of the session into")
+	fs.StringVar(&s.Replay, "replay", "", "Directory of a recording to replay instead of connecting to a cluster")
+
+	fs.StringVar(&s.Auth.User, "user", os.Getenv("ES_USER"), "Elasticsearch username (prompts for the password if none is given)")
+	fs.StringVar(&s.Auth.Password, "password", os.Getenv("ES_PASSWORD"), "Elasticsearch password")
+	fs.StringVar(&s.Auth.PasswordFile, "password-file", "", "File to read the Elasticsearch password from")
+	fs.StringVar(&s.Auth.APIKey, "apikey", os.Getenv("ES_API_KEY"), "Elasticsearch API key")
+	fs.StringVar(&s.Auth.APIKeyFile, "apikey-file", "", "File to read the Elasticsearch API key from")
+	fs.StringVar(&s.Auth.Token, "token", os.Getenv("ES_TOKEN"), "Bearer token, e.g. a service account token")
+	fs.StringVar(&s.Auth.TokenFile, "token-file", "", "File to read the bearer token from")
+
+	fs.StringVar(&s.TLS.CAFile, "cacert", "", "PEM file with the CA certificates to trust")
+	fs.StringVar(&s.TLS.CertFile, "cert", "", "Client certificate for mutual TLS")
+	fs.StringVar(&s.TLS.KeyFile, "key", "", "Key of the client certificate")
+	fs.StringVar(&s.TLS.ServerName, "server-name", "", "Server name to verify the certificate against")
+	fs.StringVar(&s.TLS.MinVersion, "tls-min-version", "1.2", "Minimum TLS version (1.0, 1.1, 1.2 or 1.3)")
+	fs.BoolVar(&s.TLS.Insecure, "insecure", false, "Skip TLS certificate verification")
+}
+
+// loadSettings parses the command line arguments and returns the settings of
+// every selected profile, or of the arguments alone when no profile is
+// selected. Each profile is applied to its own copy of the flags, so profiles
+// never leak settings into each other.
+func loadSettings(args []string) ([]Settings, error) {
+	var base Settings
+	registerFlags(flag.CommandLine, &base)
+	flag.CommandLine.Parse(args)
+
+	explicit := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) {
+		explicit[f.Name] = true
+	})
+
+	path := base.Config
+	if path == "" {
+		path = defaultConfigPath()
+	}
+	cfg := &Config{}
+	if path != "" {
+		var err error
+		if cfg, err = LoadConfig(path, explicit["config"]); err != nil {
+			return nil, err
+		}
+	}
+
+	names := profileNames(cfg, base.Profile)
+	if len(names) == 0 {
+		base.Name = base.displayName()
+		return []Settings{base}, nil
+	}
+
+	all := make([]Settings, 0, len(names))
+	for _, name := range names {
+		profile, err := cfg.Profile(name)
+		if err != nil {
+			return nil, err
+		}
+
+		var s Settings
+		fs := flag.NewFlagSet(name, flag.ContinueOnError)
+		registerFlags(fs, &s)
+		// Already parsed once above, so this cannot fail
+		fs.Parse(args)
+
+		if err := applyProfile(fs, explicit, profile); err != nil {
+			return nil, fmt.Errorf("profile %s: %w", name, err)
+		}
+		s.Name = name
+		all = append(all, s)
+	}
+
+	// Several clusters are recorded side by side, one directory each
+	if len(all) > 1 {
+		for i := range all {
+			if all[i].Record != "" {
+				all[i].Record = filepath.Join(all[i].Record, all[i].Name)
+			}
+			if all[i].Replay != "" {
+				all[i].Replay = filepath.Join(all[i].Replay, all[i].Name)
+			}
+		}
+	}
+	return all, nil
+}
+
+// profileNames splits the -profile list, expanding "all" to every profile
+// in the config file. Without -profile it is the file's default, if any.
+func profileNames(cfg *Config, list string) []string {
+	if list == "" {
+		list = cfg.Default
+	}
+	if list == "all" {
+		names := make([]string, 0, len(cfg.Profiles))
+		for name := range cfg.Profiles {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return names
+	}
+
+	var names []string
+	for _, name := range strings.Split(list, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// flagGroups are flags that only make sense together
+var flagGroups = [][]string{
+	{"user", "password", "password-file", "apikey", "apikey-file", "token", "token-file"},
+	{"host", "port", "url", "cloud-id"},
+}
+
+// clearedValue is what a flag of a group is set to when the group comes from
+// a profile that leaves it out: empty for strings, which drops any value
+// from the environment, and the default for the rest, as e.g. -port cannot
+// be empty
+func clearedValue(f *flag.Flag) string {
+	if getter, ok := f.Value.(flag.Getter); ok {
+		if _, isString := getter.Get().(string); isString {
+			return ""
+		}
+	}
+	return f.DefValue
+}
+
+// applyProfile sets every flag of fs the profile defines that was not given
+// explicitly on the command line
+func applyProfile(fs *flag.FlagSet, explicit map[string]bool, profile *Profile) error {
+	values := profile.flagValues()
+
+	// Credentials and the cluster address are each taken as a whole from a
+	// single source: given on the command line they replace the profile's,
+	// and the profile's replace the environment's, so e.g. an exported
+	// ES_API_KEY does not clash with a password from the profile
+	for _, group := range flagGroups {
+		fromFlags, fromProfile := false, false
+		for _, f := range group {
+			fromFlags = fromFlags || explicit[f]
+			fromProfile = fromProfile || values[f] != ""
+		}
+		for _, f := range group {
+			switch {
+			case fromFlags:
+				delete(values, f)
+			case fromProfile && values[f] == "":
+				values[f] = clearedValue(fs.Lookup(f))
+			}
+		}
+	}
+
+	for f, value := range values {
+		if explicit[f] {
+			continue
+		}
+		if err := fs.Set(f, value); err != nil {
+			return fmt.Errorf("invalid %s %q: %w", f, value, err)
+		}
+	}
+	return nil
+}
+
+// NewCollector validates the connection settings and builds the collector
+// polling this cluster. Rates are derived over each of windows and the
+// latest release is looked up with versions, unless disabled.
+func (s Settings) NewCollector(windows []time.Duration, versions *versionChecker) (*Collector, error) {
+	if s.Interval < minInterval {
+		return nil, fmt.Errorf("interval must be at least %s", minInterval)
+	}
+
+	if s.RateWindow < time.Second {
+		return nil, errors.New("rate window must be at least 1s")
+	}
+
+	if s.Replay != "" {
+		return s.replayCollector(windows)
+	}
+
+	seeds, err := s.seedURLs()
+	if err != nil {
+		return nil, err
+	}
+
+	// Sniffed nodes are contacted directly at their publish address, which
+	// is out of reach behind Elastic Cloud's proxy or a path prefix
+	if s.Sniff {
+		if s.CloudID != "" {
+			return nil, errors.New("-sniff cannot be used with -cloud-id")
+		}
+		for _, seed := range seeds {
+			if u, _ := url.Parse(seed); u.Path != "" {
+				return nil, fmt.Errorf("-sniff cannot be used with the path prefix of %s", seed)
+			}
+		}
+	}
+
+	// Without any credentials the cluster is accessed anonymously, which is
+	// what local clusters with security disabled expect
+	seedURL, _ := url.Parse(seeds[0])
+	auth, err := s.Auth.Resolve(seedURL.Hostname())
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig, err := s.TLS.Config()
+	if err != nil {
+		return nil, err
+	}
+
+	proxy, err := s.proxyFunc()
+	if err != nil {
+		return nil, err
+	}
+
+	if s.ReleaseFeed != "" {
+		if u, err := url.Parse(s.ReleaseFeed); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return nil, fmt.Errorf("invalid release feed %q, expected an http(s) URL", s.ReleaseFeed)
+		}
+	}
+
+	config := ClientConfig{
+		URLs:    seeds,
+		Sniff:   s.Sniff,
+		Auth:    auth,
+		Timeout: s.Timeout,
+		Transport: &http.Transport{
+			Proxy:           proxy,
+			TLSClientConfig: tlsConfig,
+		},
+	}
+	if s.Record != "" {
+		recorder, err := NewRecorder(s.Record, seeds)
+		if err != nil {
+			return nil, err
+		}
+		config.OnResponse = recorder.OnResponse
+	}
+
+	client, err := NewClient(config)
+	if err != nil {
+		return nil, err
+	}
+	collector := NewCollector(client, s.Interval, windows)
+	if !s.NoVersionCheck {
+		collector.CheckVersions(versions, s.ReleaseFeed, s.releaseClient(proxy, tlsConfig))
+	}
+	return collector, nil
+}
+
+// replayCollector builds a collector that replays the recording in -replay.
+// Nothing is sent over the network, so the connection settings, sniffing and
+// the version check are left out.
+func (s Settings) replayCollector(windows []time.Duration) (*Collector, error) {
+	replay, err := OpenReplay(s.Replay)
+	if err != nil {
+		return nil, err
+	}
+	client, err := NewClient(ClientConfig{
+		URLs:      []string{replayURL},
+		Transport: replay,
+	})
+	if err != nil {
+		return nil, err
+	}
+	collector := NewCollector(client, s.Interval, windows)
+	collector.Replay(replay)
+	return collector, nil
+}
+
+// releaseClient returns the client looking up the latest release. It goes
+// through the proxy of the cluster. The TLS settings only apply to a custom
+// -release-feed, such as an internal mirror: the CA bundle of -cacert would
+// leave the public feeds unverifiable.
+func (s Settings) releaseClient(proxy func(*http.Request) (*url.URL, error), tlsConfig *tls.Config) *http.Client {
+	transport := &http.Transport{Proxy: proxy}
+	if s.ReleaseFeed != "" {
+		transport.TLSClientConfig = tlsConfig
+	}
+	return &http.Client{Timeout: 10 * time.Second, Transport: transport}
+}
+
+// seedURLs returns the base URLs of the cluster: the endpoint of the cloud
+// ID, else the -url list, else the -host list with -port added to hosts
+// given without one
+func (s Settings) seedURLs() ([]string, error) {
+	if s.CloudID != "" {
+		u, err := decodeCloudID(s.CloudID)
+		if err != nil {
+			return nil, err
+		}
+		return []string{u}, nil
+	}
+
+	hosts, withPort := s.Host, false
+	if s.URL != "" {
+		hosts, withPort = s.URL, true
+	}
+
+	var seeds []string
+	for _, host := range strings.Split(hosts, ",") {
+		// Strip any trailing slash from the host
+		host = strings.TrimRight(strings.TrimSpace(host), "/")
+		if host == "" {
+			continue
+		}
+
+		// Validate and process the host URL
+		u, err := url.Parse(host)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("invalid URL %q, it must start with http:// or https://", host)
+		}
+		if u.RawQuery != "" || u.Fragment != "" {
+			return nil, fmt.Errorf("URL %q must not have a query or fragment", host)
+		}
+		if !withPort && u.Path != "" {
+			return nil, fmt.Errorf("host %q has a path, use -url for clusters behind a path prefix", host)
+		}
+		if !withPort && u.Port() == "" {
+			host = fmt.Sprintf("%s:%d", host, s.Port)
+		}
+		seeds = append(seeds, host)
+	}
+
+	if len(seeds) == 0 {
+		return nil, errors.New("no host given")
+	}
+	return seeds, nil
+}
+
+// proxyFunc returns the proxy to use: -proxy if given, else whatever the
+// HTTPS_PROXY, HTTP_PROXY and NO_PROXY environment variables say
+func (s Settings) proxyFunc() (func(*http.Request) (*url.URL, error), error) {
+	if s.Proxy == "" {
+		return http.ProxyFromEnvironment, nil
+	}
+
+	u, err := url.Parse(s.Proxy)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy %q, expected e.g. http://proxy:3128 or socks5://proxy:1080", s.Proxy)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %q, use http, https or socks5", u.Scheme)
+	}
+	return http.ProxyURL(u), nil
+}
+
+// displayName names the cluster by its address when it has no profile name
+func (s Settings) displayName() string {
+	if s.CloudID != "" {
+		name, _, _ := strings.Cut(s.CloudID, ":")
+		return name
+	}
+	address := s.Host
+	if s.URL != "" {
+		address = s.URL
+	}
+	return strings.TrimPrefix(strings.TrimPrefix(address, "https://"), "http://")
+}
+
+// decodeCloudID returns the Elasticsearch URL of an Elastic Cloud ID, which
+// is the deployment name and base64("host[:port]$es-id$kibana-id") joined by
+// a colon
+func decodeCloudID(cloudID string) (string, error) {
+	_, encoded, found := strings.Cut(cloudID, ":")
+	if !found {
+		encoded = cloudID
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		// Some tools strip the padding
+		decoded, err = base64.RawStdEncoding.DecodeString(strings.TrimRight(encoded, "="))
+	}
+	if err != nil {
+		return "", fmt.Errorf("invalid cloud ID: %w", err)
+	}
+
+	parts := strings.Split(string(decoded), "$")
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return "", errors.New("invalid cloud ID: expected host$elasticsearch-id$kibana-id")
+	}
+
+	// The port, if any, belongs to the host and comes after the deployment id
+	host, port, _ := strings.Cut(parts[0], ":")
+	if port == "" {
+		port = "443"
+	}
+	return fmt.Sprintf("https://%s.%s:%s", parts[1], host, port), nil
+}
+
+// expandHome replaces a leading ~ with the user's home directory
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[1:])
+}
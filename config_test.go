@@ -0,0 +1,259 @@
+package main
+
+import (
+	"flag"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDecodeCloudID(t *testing.T) {
+	tests := []struct {
+		name    string
+		cloudID string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "default port",
+			cloudID: "prod:dXMtZWFzdC0xLmF3cy5mb3VuZC5pbyRhYmMxMjMka2liNDU2",
+			want:    "https://abc123.us-east-1.aws.found.io:443",
+		},
+		{
+			name:    "port after the host",
+			cloudID: "prod:ZXUtd2VzdC0xLmF3cy5mb3VuZC5pbzo5MjQzJGFiYzEyMyRraWI0NTY=",
+			want:    "https://abc123.eu-west-1.aws.found.io:9243",
+		},
+		{
+			name:    "padding stripped",
+			cloudID: "prod:ZXUtd2VzdC0xLmF3cy5mb3VuZC5pbzo5MjQzJGFiYzEyMyRraWI0NTY",
+			want:    "https://abc123.eu-west-1.aws.found.io:9243",
+		},
+		{
+			name:    "without a name",
+			cloudID: "dXMtZWFzdC0xLmF3cy5mb3VuZC5pbyRhYmMxMjM=",
+			want:    "https://abc123.us-east-1.aws.found.io:443",
+		},
+		{name: "not base64", cloudID: "prod:not base64!", wantErr: true},
+		{name: "no deployment id", cloudID: "prod:dXMtZWFzdC0xLmF3cy5mb3VuZC5pbw==", wantErr: true},
+		{name: "no host", cloudID: "prod:JGFiYzEyMyRraWI=", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeCloudID(tt.cloudID)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("decodeCloudID(%q) error = %v, want an error: %v", tt.cloudID, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("decodeCloudID(%q) = %q, want %q", tt.cloudID, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSeedURLs(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings Settings
+		want     []string
+		wantErr  bool
+	}{
+		{
+			name:     "host and port",
+			settings: Settings{Host: "http://localhost", Port: 9200},
+			want:     []string{"http://localhost:9200"},
+		},
+		{
+			name:     "hosts with and without a port",
+			settings: Settings{Host: "https://es-1, https://es-2:9243/", Port: 9200},
+			want:     []string{"https://es-1:9200", "https://es-2:9243"},
+		},
+		{
+			name:     "URL with a path prefix",
+			settings: Settings{Host: "http://localhost", Port: 9200, URL: "https://gw.example.com/es/"},
+			want:     []string{"https://gw.example.com/es"},
+		},
+		{
+			name:     "URL without a port",
+			settings: Settings{Port: 9200, URL: "https://es.example.com"},
+			want:     []string{"https://es.example.com"},
+		},
+		{
+			name:     "cloud ID over the URL",
+			settings: Settings{URL: "https://gw.example.com", CloudID: "prod:dXMtZWFzdC0xLmF3cy5mb3VuZC5pbyRhYmMxMjMka2liNDU2"},
+			want:     []string{"https://abc123.us-east-1.aws.found.io:443"},
+		},
+		{name: "no scheme", settings: Settings{Host: "localhost", Port: 9200}, wantErr: true},
+		{name: "host with a path", settings: Settings{Host: "http://localhost/es", Port: 9200}, wantErr: true},
+		{name: "URL with a query", settings: Settings{URL: "http://localhost:9200?pretty"}, wantErr: true},
+		{name: "nothing", settings: Settings{Host: " , ", Port: 9200}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.settings.seedURLs()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("seedURLs error = %v, want an error: %v", err, tt.wantErr)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("seedURLs = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProxyFunc(t *testing.T) {
+	tests := []struct {
+		proxy   string
+		want    string
+		wantErr bool
+	}{
+		{proxy: "http://proxy:3128", want: "http://proxy:3128"},
+		{proxy: "socks5://localhost:1080", want: "socks5://localhost:1080"},
+		{proxy: "socks5h://localhost:1080", want: "socks5h://localhost:1080"},
+		{proxy: "proxy:3128", wantErr: true},
+		{proxy: "ftp://proxy:21", wantErr: true},
+	}
+
+	req, _ := http.NewRequest("GET", "https://es.example.com:9200/", nil)
+	for _, tt := range tests {
+		proxy, err := Settings{Proxy: tt.proxy}.proxyFunc()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("proxyFunc for %q error = %v, want an error: %v", tt.proxy, err, tt.wantErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		u, err := proxy(req)
+		if err != nil || u.String() != tt.want {
+			t.Errorf("proxy for %q = %v, %v, want %s", tt.proxy, u, err, tt.want)
+		}
+	}
+}
+
+func TestApplyProfile(t *testing.T) {
+	// What the flags end up as that the profiles below touch
+	type result struct {
+		host, url        string
+		port             int
+		user, password   string
+		apiKey           string
+		interval, window time.Duration
+	}
+	tests := []struct {
+		name    string
+		env     map[string]string
+		args    []string
+		profile Profile
+		want    result
+	}{
+		{
+			name:    "profile credentials replace the environment's",
+			env:     map[string]string{"ES_API_KEY": "env-key"},
+			profile: Profile{User: "elastic", Password: "secret"},
+			want:    result{host: "http://localhost", port: 9200, user: "elastic", password: "secret", interval: 5 * time.Second, window: time.Minute},
+		},
+		{
+			name:    "flag credentials replace the profile's",
+			args:    []string{"-apikey", "flag-key"},
+			profile: Profile{User: "elastic", Password: "secret"},
+			want:    result{host: "http://localhost", port: 9200, apiKey: "flag-key", interval: 5 * time.Second, window: time.Minute},
+		},
+		{
+			name:    "profile without credentials",
+			env:     map[string]string{"ES_API_KEY": "env-key"},
+			profile: Profile{Interval: 30 * time.Second},
+			want:    result{host: "http://localhost", port: 9200, apiKey: "env-key", interval: 30 * time.Second, window: time.Minute},
+		},
+		{
+			name:    "profile URL replaces the environment's",
+			env:     map[string]string{"ES_URL": "http://other:9200"},
+			profile: Profile{URL: "https://es.example.com", URLs: []string{"https://es-2.example.com"}},
+			want:    result{port: 9200, url: "https://es.example.com,https://es-2.example.com", interval: 5 * time.Second, window: time.Minute},
+		},
+		{
+			name:    "flag host replaces the profile's URL",
+			args:    []string{"-host", "http://es-1"},
+			profile: Profile{URL: "https://es.example.com"},
+			want:    result{host: "http://es-1", port: 9200, interval: 5 * time.Second, window: time.Minute},
+		},
+		{
+			name:    "flag port replaces the profile's URL",
+			args:    []string{"-port", "9300"},
+			profile: Profile{URL: "https://es.example.com"},
+			want:    result{host: "http://localhost", port: 9300, interval: 5 * time.Second, window: time.Minute},
+		},
+		{
+			name:    "flags win over the profile",
+			args:    []string{"-interval", "10s"},
+			profile: Profile{Interval: 30 * time.Second, RateWindow: 5 * time.Minute},
+			want:    result{host: "http://localhost", port: 9200, interval: 10 * time.Second, window: 5 * time.Minute},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The environment is read when the flags are registered
+			for _, name := range []string{"ES_URL", "ES_CLOUD_ID", "ES_INTERVAL", "ES_USER", "ES_PASSWORD", "ES_API_KEY", "ES_TOKEN"} {
+				t.Setenv(name, tt.env[name])
+			}
+
+			var s Settings
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			registerFlags(fs, &s)
+			if err := fs.Parse(tt.args); err != nil {
+				t.Fatal(err)
+			}
+			explicit := make(map[string]bool)
+			fs.Visit(func(f *flag.Flag) { explicit[f.Name] = true })
+
+			if err := applyProfile(fs, explicit, &tt.profile); err != nil {
+				t.Fatalf("applyProfile: %v", err)
+			}
+			got := result{
+				host:     s.Host,
+				url:      s.URL,
+				port:     s.Port,
+				user:     s.Auth.User,
+				password: s.Auth.Password,
+				apiKey:   s.Auth.APIKey,
+				interval: s.Interval,
+				window:   s.RateWindow,
+			}
+			if got != tt.want {
+				t.Errorf("settings = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewCollectorSniff(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings Settings
+		refused  bool // Whether -sniff is refused
+	}{
+		{name: "seeds", settings: Settings{Host: "http://es-1,http://es-2", Port: 9200}},
+		{name: "path prefix", settings: Settings{URL: "https://gw.example.com/es"}, refused: true},
+		{name: "cloud ID", settings: Settings{CloudID: "prod:dXMtZWFzdC0xLmF3cy5mb3VuZC5pbyRhYmMxMjMka2liNDU2"}, refused: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("NETRC", filepath.Join(t.TempDir(), "netrc"))
+			s := tt.settings
+			s.Sniff, s.NoVersionCheck = true, true
+			s.Interval, s.RateWindow = 5*time.Second, time.Minute
+			s.TLS.MinVersion = "1.2"
+			_, err := s.NewCollector(defaultRateWindows, nil)
+			if refused := err != nil && strings.Contains(err.Error(), "-sniff"); refused != tt.refused || (err != nil && !refused) {
+				t.Errorf("NewCollector error = %v, want -sniff refused: %v", err, tt.refused)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// detailLoader fetches what a detail screen shows from the cluster on screen
+// and returns the function writing it into the screen, which runs on the UI
+// goroutine. The detail screens drill down into a single index or node: they
+// fetch their content when they open and on 'r' rather than on every poll.
+//
+// What they fetch, such as an IndexDetail or a NodeDetail, records the
+// failure of every request in Errors, keyed by path like Snapshot.Errors.
+// A failed request leaves its part of the detail empty.
+type detailLoader func(ctx context.Context, client *Client, dist Distribution) func(view *tview.TextView)
+
+// fetchAll decodes the response of every path of requests into its target,
+// all in parallel, and records the failed ones in errs
+func fetchAll(ctx context.Context, client *Client, requests map[string]interface{}, errs map[string]error) {
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	for path, target := range requests {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := client.Get(ctx, path, target); err != nil {
+				mu.Lock()
+				errs[path] = err
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// detailStatus writes when a detail was fetched and the requests that failed
+func detailStatus(view io.Writer, fetchedAt time.Time, errs map[string]error) {
+	fmt.Fprintf(view, "[#666666]Fetched %s, press 'r' to refresh, Esc to go back[white]\n\n", fetchedAt.Format("15:04:05"))
+	paths := make([]string, 0, len(errs))
+	for path := range errs {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	if badges := errorBadges(errs, paths...); badges != "" {
+		fmt.Fprint(view, badges+"\n")
+	}
+}
+
+// detailSection starts a section of a detail screen
+func detailSection(view io.Writer, title string) {
+	fmt.Fprintf(view, "[::b][#00ffff]%s[::-][white]\n", title)
+}
+
+// detailRow writes a named value of a section
+func detailRow(view io.Writer, name, format string, args ...interface{}) {
+	fmt.Fprintf(view, "  [#00ffff]%-22s[white] %s\n", name+":", fmt.Sprintf(format, args...))
+}
+
+// formatCount formats a counter with thousands separators
+func formatCount(n int64) string {
+	return formatNumber(int(n))
+}
+
+// openDetail covers the dashboard with a detail screen filled by load
+func (a *App) openDetail(title string, load detailLoader) {
+	a.detailLoad = load
+	a.detailFrom = a.tv.GetFocus()
+	a.detail.SetTitle(" " + title + " ")
+	a.detail.SetText("[#666666]Loading...[white]").ScrollToBeginning()
+	a.theme.recolorPanels(a.detail)
+	a.pages.ShowPage("detail")
+	a.tv.SetFocus(a.detail)
+	a.loadDetail()
+}
+
+// loadDetail fetches the content of the open detail screen in the
+// background, keeping what it shows until the answer is in
+func (a *App) loadDetail() {
+	a.detailSeq++
+	seq, load := a.detailSeq, a.detailLoad
+
+	dist := defaultDistribution
+	ctx, cancel := context.WithTimeout(context.Background(), pollTimeout)
+	if a.shown != nil && a.shown.snap != nil {
+		snap := a.shown.snap
+		dist = snap.Distribution
+		// Recordings and replays keep the requests with the poll on screen
+		ctx = withPollTime(ctx, snap.PolledAt)
+	}
+
+	client := a.collector.client
+	go func() {
+		defer cancel()
+		render := load(ctx, client, dist)
+		a.tv.QueueUpdateDraw(func() {
+			// The screen was closed or reloaded since
+			if seq != a.detailSeq {
+				return
+			}
+			render(a.detail)
+			a.theme.recolorPanels(a.detail)
+		})
+	}()
+}
+
+// closeDetail goes back to the dashboard
+func (a *App) closeDetail() {
+	a.detailSeq++
+	a.detailLoad = nil
+	a.pages.HidePage("detail")
+	a.tv.SetFocus(a.detailFrom)
+}
+
+// handleDetailKey handles the keys of an open detail screen. The others
+// scroll it.
+func (a *App) handleDetailKey(event *tcell.EventKey) *tcell.EventKey {
+	switch {
+	case event.Key() == tcell.KeyEsc, event.Key() == tcell.KeyBackspace, event.Key() == tcell.KeyBackspace2:
+		a.closeDetail()
+	case event.Key() == tcell.KeyRune && event.Rune() == 'q':
+		a.tv.Stop()
+	case event.Key() == tcell.KeyRune && event.Rune() == 'r':
+		a.loadDetail()
+	default:
+		return event
+	}
+	return nil
+}
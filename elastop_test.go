@@ -0,0 +1,86 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormatUptime(t *testing.T) {
+	tests := []struct {
+		millis int64
+		want   string
+	}{
+		{0, "0m    "},
+		{59 * 1000, "0m    "},
+		{45 * 60 * 1000, "45m   "},
+		{3600 * 1000, "1h0m  "},
+		{(23*3600 + 59*60) * 1000, "23h59m"},
+		{(7*24 + 3) * 3600 * 1000, "7d3h  "},
+		{(400*24 + 5) * 3600 * 1000, "400d5h"},
+	}
+	for _, tt := range tests {
+		got := stripTags(formatUptime(tt.millis))
+		if got != tt.want {
+			t.Errorf("formatUptime(%d) = %q, want %q", tt.millis, got, tt.want)
+		}
+	}
+}
+
+func TestFormatResourceSize(t *testing.T) {
+	tests := []struct {
+		bytes int64
+		want  string
+	}{
+		{0, "   0 B"},
+		{1023, "1023 B"},
+		{1024, "  1K"},
+		{1536, "  1K"},
+		{512 * 1024 * 1024, "512M"},
+		{64 << 30, " 64G"},
+		{2000 << 30, "  1T"},
+		{3 << 50, "  3P"},
+	}
+	for _, tt := range tests {
+		if got := formatResourceSize(tt.bytes); got != tt.want {
+			t.Errorf("formatResourceSize(%d) = %q, want %q", tt.bytes, got, tt.want)
+		}
+	}
+}
+
+func TestParseStoreSize(t *testing.T) {
+	tests := []struct {
+		size string
+		want int64
+	}{
+		{"0b", 0},
+		{"512b", 512},
+		{"1kb", 1024},
+		{"1.5mb", 1536 * 1024},
+		{"221.5gb", 443 << 29},
+		{"2tb", 2 << 40},
+		{"3pb", 3 << 50},
+		{"", 0},
+		{"12xb", 0},
+	}
+	for _, tt := range tests {
+		if got := parseStoreSize(tt.size); got != tt.want {
+			t.Errorf("parseStoreSize(%q) = %d, want %d", tt.size, got, tt.want)
+		}
+	}
+}
+
+// stripTags removes tview color and style tags such as [#ff99cc] and [::b]
+func stripTags(s string) string {
+	var b strings.Builder
+	for {
+		start := strings.IndexByte(s, '[')
+		end := strings.IndexByte(s, ']')
+		if start < 0 || end < start {
+			break
+		}
+		b.WriteString(s[:start])
+		s = s[end+1:]
+	}
+	b.WriteString(s)
+	return b.String()
+}
@@ -0,0 +1,281 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// exportedCluster is one cluster behind the exporter. The collector calls
+// back from its own goroutine while scrapes come from the HTTP server, so
+// the index activities are guarded by mu.
+type exportedCluster struct {
+	name       string
+	collector  *Collector
+	rateWindow time.Duration
+
+	mu         sync.Mutex
+	tracked    *Snapshot // Last snapshot counted into activities
+	activities map[string]*IndexActivity
+}
+
+// track counts the current snapshot into the index activities, once
+func (c *exportedCluster) track() {
+	snap, _, _ := c.collector.State()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if snap != nil && snap != c.tracked {
+		trackIndexActivity(c.activities, snap.IndicesStats)
+		c.tracked = snap
+	}
+}
+
+// Exporter serves the values the dashboard computes, rates included, in the
+// Prometheus text format, so they can be graphed next to everything else.
+// The clusters are polled in the background just like in the dashboard and
+// a scrape only renders the latest snapshots.
+type Exporter struct {
+	clusters []*exportedCluster
+}
+
+func NewExporter(clusters []*Cluster, rateWindows []time.Duration) *Exporter {
+	e := &Exporter{}
+	for i, cluster := range clusters {
+		e.clusters = append(e.clusters, &exportedCluster{
+			name:       cluster.Name,
+			collector:  cluster.collector,
+			rateWindow: rateWindows[i],
+			activities: make(map[string]*IndexActivity),
+		})
+	}
+	return e
+}
+
+// ListenAndServe starts polling and serves /metrics on addr until it fails
+func (e *Exporter) ListenAndServe(addr string) error {
+	for _, cluster := range e.clusters {
+		go cluster.collector.Run(cluster.track)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", e)
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, "elastop exporter, metrics are at /metrics\n")
+	})
+
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	return server.ListenAndServe()
+}
+
+func (e *Exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	e.WriteMetrics(w)
+}
+
+// WriteMetrics writes the metrics of every cluster to w
+func (e *Exporter) WriteMetrics(w io.Writer) {
+	m := newMetricSet()
+	for _, cluster := range e.clusters {
+		cluster.writeMetrics(m)
+	}
+	m.write(w)
+}
+
+func (c *exportedCluster) writeMetrics(m *metricSet) {
+	snap, stale, err := c.collector.State()
+	cl := label{"cluster", c.name}
+
+	m.gauge("elastop_up", "Whether the last poll of the cluster succeeded", boolValue(snap != nil && err == nil), cl)
+	m.gauge("elastop_stale", "Whether the exported values are older than the poll interval", boolValue(stale), cl)
+	if snap == nil {
+		return
+	}
+
+	c.mu.Lock()
+	report := buildReport(snap, c.rateWindow, c.activities)
+	c.mu.Unlock()
+
+	m.gauge("elastop_last_poll_timestamp_seconds", "When the exported values were collected", float64(snap.FetchedAt.UnixNano())/1e9, cl)
+	m.gauge("elastop_rate_window_seconds", "Window the exported rates are averaged over", c.rateWindow.Seconds(), cl)
+	for _, path := range []string{endpointClusterStats, endpointNodesInfo, endpointNodesStats, endpointIndices, endpointIndexStats, endpointClusterHealth, endpointDataStreams} {
+		m.gauge("elastop_endpoint_up", "Whether the endpoint answered the last poll", boolValue(!snap.Failed(path)), cl, label{"endpoint", path})
+	}
+
+	// Cluster
+	for _, status := range []string{"green", "yellow", "red"} {
+		m.gauge("elastop_cluster_status", "Health of the cluster, 1 for the current status", boolValue(report.Cluster.Status == status), cl, label{"status", status})
+	}
+	m.gauge("elastop_cluster_nodes", "Nodes in the cluster", float64(report.Cluster.NodesTotal), cl)
+	m.gauge("elastop_cluster_nodes_failed", "Nodes that failed to answer the cluster stats", float64(report.Cluster.NodesFailed), cl)
+	m.gauge("elastop_cluster_lagging_nodes", "Nodes running an older version than the newest one", float64(len(report.Cluster.LaggingNodes)), cl)
+
+	// Nodes
+	for _, node := range report.Nodes {
+		nl := label{"node", node.Name}
+		m.gauge("elastop_node_cpu_percent", "CPU usage of the node", float64(node.CPUPercent), cl, nl)
+		m.gauge("elastop_node_memory_percent", "Memory usage of the node", node.MemoryPercent, cl, nl)
+		m.gauge("elastop_node_heap_percent", "JVM heap usage of the node", node.HeapPercent, cl, nl)
+		m.gauge("elastop_node_disk_percent", "Usage of the node's data path", node.DiskPercent, cl, nl)
+		m.gauge("elastop_node_heap_used_bytes", "JVM heap used by the node", float64(node.HeapUsedBytes), cl, nl)
+		m.gauge("elastop_node_disk_used_bytes", "Bytes used on the node's data path", float64(node.DiskUsedBytes), cl, nl)
+		if load, ok := node.LoadAverage["1m"]; ok {
+			m.gauge("elastop_node_load1", "One minute load average of the node", load, cl, nl)
+		}
+		m.counter("elastop_node_gc_collections_total", "Garbage collections of the node", float64(node.GCYoungCount), cl, nl, label{"gc", "young"})
+		m.counter("elastop_node_gc_collections_total", "Garbage collections of the node", float64(node.GCOldCount), cl, nl, label{"gc", "old"})
+		m.counter("elastop_node_gc_time_seconds_total", "Time the node spent in garbage collection", float64(node.GCYoungMillis)/1000, cl, nl, label{"gc", "young"})
+		m.counter("elastop_node_gc_time_seconds_total", "Time the node spent in garbage collection", float64(node.GCOldMillis)/1000, cl, nl, label{"gc", "old"})
+		m.gauge("elastop_node_uptime_seconds", "JVM uptime of the node", float64(node.UptimeMillis)/1000, cl, nl)
+	}
+
+	// Indices
+	for _, index := range report.Indices {
+		il := label{"index", index.Name}
+		m.gauge("elastop_index_docs", "Documents in the index", float64(index.Docs), cl, il)
+		m.gauge("elastop_index_indexing_rate", "Documents indexed per second over the rate window", index.IndexingRate, cl, il)
+		m.gauge("elastop_index_ingested_docs", "Documents added to the index since the exporter started", float64(index.IngestedDocs), cl, il)
+		m.counter("elastop_index_indexing_total", "Indexing operations on the index", float64(index.IndexTotal), cl, il)
+	}
+
+	// Shards
+	if shards := report.Shards; shards != nil {
+		for _, s := range []struct {
+			state string
+			count int
+		}{
+			{"active", shards.Active},
+			{"primary", shards.Primary},
+			{"relocating", shards.Relocating},
+			{"initializing", shards.Initializing},
+			{"unassigned", shards.Unassigned},
+		} {
+			m.gauge("elastop_shards", "Shards of the cluster by state", float64(s.count), cl, label{"state", s.state})
+		}
+		m.gauge("elastop_shards_active_percent", "Share of the shards that are active", shards.ActivePercent, cl)
+	}
+
+	// Metrics panel
+	metrics := report.Metrics
+	optional := func(name, help string, value *float64) {
+		if value != nil {
+			m.gauge(name, help, *value, cl)
+		}
+	}
+	optional("elastop_cpu_percent", "CPU usage across the cluster", intValue(metrics.CPUPercent))
+	optional("elastop_disk_percent", "Disk usage across the cluster", metrics.DiskPercent)
+	optional("elastop_heap_percent", "JVM heap usage across the cluster", metrics.HeapPercent)
+	optional("elastop_memory_percent", "Memory usage across the cluster", metrics.MemoryPercent)
+	optional("elastop_network_tx_bytes_per_second", "Transport bytes sent per second over the rate window", metrics.NetworkTXRate)
+	optional("elastop_network_rx_bytes_per_second", "Transport bytes received per second over the rate window", metrics.NetworkRXRate)
+	optional("elastop_http_connections", "Open HTTP connections across the cluster", int64Value(metrics.HTTPConnections))
+	optional("elastop_query_rate", "Search queries per second over the rate window", metrics.QueryRate)
+	optional("elastop_cluster_indexing_rate", "Indexing operations per second across the whole cluster over the rate window", metrics.IndexRate)
+	optional("elastop_snapshots", "Snapshots in the cluster", intValue(metrics.Snapshots))
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func intValue(v *int) *float64 {
+	if v == nil {
+		return nil
+	}
+	return ptr(float64(*v))
+}
+
+func int64Value(v *int64) *float64 {
+	if v == nil {
+		return nil
+	}
+	return ptr(float64(*v))
+}
+
+type label struct {
+	name, value string
+}
+
+type metricSample struct {
+	labels []label
+	value  float64
+}
+
+type metricFamily struct {
+	name, help, kind string
+	samples          []metricSample
+}
+
+// metricSet collects samples by metric name, since the text format wants all
+// samples of a metric in one block while they are produced cluster by cluster
+type metricSet struct {
+	families map[string]*metricFamily
+}
+
+func newMetricSet() *metricSet {
+	return &metricSet{families: make(map[string]*metricFamily)}
+}
+
+func (m *metricSet) add(kind, name, help string, value float64, labels []label) {
+	family, ok := m.families[name]
+	if !ok {
+		family = &metricFamily{name: name, help: help, kind: kind}
+		m.families[name] = family
+	}
+	family.samples = append(family.samples, metricSample{labels: labels, value: value})
+}
+
+func (m *metricSet) gauge(name, help string, value float64, labels ...label) {
+	m.add("gauge", name, help, value, labels)
+}
+
+func (m *metricSet) counter(name, help string, value float64, labels ...label) {
+	m.add("counter", name, help, value, labels)
+}
+
+// write writes the metrics in the Prometheus text format, sorted by name
+func (m *metricSet) write(w io.Writer) {
+	names := make([]string, 0, len(m.families))
+	for name := range m.families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		family := m.families[name]
+		fmt.Fprintf(w, "# HELP %s %s\n", name, family.help)
+		fmt.Fprintf(w, "# TYPE %s %s\n", name, family.kind)
+		for _, sample := range family.samples {
+			fmt.Fprintf(w, "%s%s %s\n", name, formatLabels(sample.labels), strconv.FormatFloat(sample.value, 'g', -1, 64))
+		}
+	}
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func formatLabels(labels []label) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	parts := make([]string, len(labels))
+	for i, l := range labels {
+		parts[i] = fmt.Sprintf(`%s="%s"`, l.name, labelEscaper.Replace(l.value))
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExporterMetrics(t *testing.T) {
+	tests := []struct {
+		name    string
+		failing []string
+	}{
+		{name: "exporter"},
+		{name: "exporter-failed", failing: []string{endpointNodesStats, endpointClusterHealth}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newFakeCluster(t, tt.failing...)
+			client, err := NewClient(ClientConfig{URLs: []string{srv.URL}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			collector := NewCollector(client, 5*time.Second, defaultRateWindows)
+			collector.poll(func() {})
+			snap, _, _ := collector.State()
+			if snap == nil {
+				t.Fatal("no snapshot polled")
+			}
+			snap.FetchedAt = fakeSnapshotTime
+
+			exporter := NewExporter([]*Cluster{NewCluster("prod", collector)}, []time.Duration{time.Minute})
+			exporter.clusters[0].track()
+			var b strings.Builder
+			exporter.WriteMetrics(&b)
+			checkGolden(t, tt.name, b.String())
+		})
+	}
+}
+
+func TestFormatLabels(t *testing.T) {
+	tests := []struct {
+		labels []label
+		want   string
+	}{
+		{nil, ""},
+		{[]label{{"cluster", "prod"}}, `{cluster="prod"}`},
+		{[]label{{"cluster", "prod"}, {"index", `logs "a"\b` + "\n"}}, `{cluster="prod",index="logs \"a\"\\b\n"}`},
+	}
+	for _, tt := range tests {
+		if got := formatLabels(tt.labels); got != tt.want {
+			t.Errorf("formatLabels(%v) = %s, want %s", tt.labels, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// fakeClusterDir holds a canned response for every endpoint elastop polls,
+// named like the files of a recording
+const fakeClusterDir = "testdata/cluster"
+
+// newFakeCluster starts a server answering like a three node Elasticsearch
+// 8 cluster with the responses in fakeClusterDir. The endpoints in failing
+// answer 503 instead.
+func newFakeCluster(t *testing.T, failing ...string) *httptest.Server {
+	t.Helper()
+
+	fail := make(map[string]bool)
+	for _, path := range failing {
+		fail[path] = true
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if fail[r.URL.RequestURI()] {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprint(w, `{"error":{"type":"cluster_block_exception","reason":"blocked by: [SERVICE_UNAVAILABLE/2/no master];"},"status":503}`)
+			return
+		}
+
+		body, err := os.ReadFile(filepath.Join(fakeClusterDir, recordName(r.URL.RequestURI())+".json"))
+		if errors.Is(err, os.ErrNotExist) {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprintf(w, `{"error":"no handler found for uri [%s]","status":404}`, r.URL.RequestURI())
+			return
+		}
+		if err != nil {
+			t.Errorf("fake cluster: %v", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write(body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// fakeSnapshotTime is when every snapshot of the fake cluster claims to have
+// been fetched, so that rendered output does not depend on the clock
+var fakeSnapshotTime = time.Date(2026, 10, 16, 9, 30, 0, 0, time.Local)
+
+// collectFake polls the fake cluster once. Its fetch time and address are
+// replaced with fixed values, as they end up on screen.
+func collectFake(t *testing.T, failing ...string) (*Collector, *Snapshot) {
+	t.Helper()
+
+	srv := newFakeCluster(t, failing...)
+	client, err := NewClient(ClientConfig{URLs: []string{srv.URL}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	collector := NewCollector(client, 5*time.Second, defaultRateWindows)
+	snap, err := collector.collect(pollTimeout)
+	if err != nil {
+		t.Fatalf("collect: %v", err)
+	}
+	snap.FetchedAt = fakeSnapshotTime
+	snap.ServedBy = "http://10.20.0.11:9200"
+	return collector, snap
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// indexFilter selects the indices the indices panel lists. It is a list of
+// space separated terms that an index must all match:
+//
+//	logs          names containing logs
+//	logs-*-2026.* names matching the wildcards * and ?
+//	/^logs-\d+$/  names matching the regular expression, the closing / is optional
+//	health:red    qualifiers comparing a column, see filterQualifiers
+//	rate:>0       numeric qualifiers take >, >=, <, <= or = (the default)
+type indexFilter struct {
+	expr  string
+	terms []func(indexInfo) bool
+}
+
+// parseIndexFilter parses the filter typed after '/'. An empty filter
+// matches every index and is returned as nil.
+func parseIndexFilter(expr string) (*indexFilter, error) {
+	filter := &indexFilter{expr: strings.TrimSpace(expr)}
+	for _, term := range strings.Fields(expr) {
+		match, err := parseFilterTerm(term)
+		if err != nil {
+			return nil, err
+		}
+		filter.terms = append(filter.terms, match)
+	}
+	if len(filter.terms) == 0 {
+		return nil, nil
+	}
+	return filter, nil
+}
+
+// match reports whether idx matches every term of f, which may be nil
+func (f *indexFilter) match(idx indexInfo) bool {
+	if f == nil {
+		return true
+	}
+	for _, match := range f.terms {
+		if !match(idx) {
+			return false
+		}
+	}
+	return true
+}
+
+func parseFilterTerm(term string) (func(indexInfo) bool, error) {
+	if strings.HasPrefix(term, "/") {
+		re, err := regexp.Compile(strings.TrimSuffix(term[1:], "/"))
+		if err != nil {
+			return nil, err
+		}
+		return func(idx indexInfo) bool { return re.MatchString(idx.index) }, nil
+	}
+
+	// Index names cannot contain a colon, so this is always a qualifier
+	if key, value, ok := strings.Cut(term, ":"); ok {
+		qualifier, known := filterQualifiers[strings.ToLower(key)]
+		if !known {
+			return nil, fmt.Errorf("unknown qualifier %q, use %s", key, strings.Join(qualifierNames(), ", "))
+		}
+		match, err := qualifier(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+		return match, nil
+	}
+
+	// Index names are always lowercase
+	term = strings.ToLower(term)
+	if strings.ContainsAny(term, "*?") {
+		pattern := regexp.QuoteMeta(term)
+		pattern = strings.NewReplacer(`\*`, ".*", `\?`, ".").Replace(pattern)
+		re := regexp.MustCompile("^" + pattern + "$")
+		return func(idx indexInfo) bool { return re.MatchString(idx.index) }, nil
+	}
+	return func(idx indexInfo) bool { return strings.Contains(idx.index, term) }, nil
+}
+
+// filterQualifiers build the match of a key:value term from its value
+var filterQualifiers = map[string]func(value string) (func(indexInfo) bool, error){
+	"health": func(value string) (func(indexInfo) bool, error) {
+		healths := make(map[string]bool)
+		for _, health := range strings.Split(strings.ToLower(value), ",") {
+			switch health {
+			case "green", "yellow", "red":
+				healths[health] = true
+			default:
+				return nil, fmt.Errorf("unknown health %q, use green, yellow or red", health)
+			}
+		}
+		return func(idx indexInfo) bool { return healths[idx.health] }, nil
+	},
+	"docs": numericQualifier(parseFilterCount, func(idx indexInfo) float64 { return float64(idx.docs) }),
+	"ingested": numericQualifier(parseFilterCount, func(idx indexInfo) float64 {
+		return float64(idx.ingested)
+	}),
+	"rate": numericQualifier(parseFilterCount, func(idx indexInfo) float64 { return idx.indexingRate }),
+	"shards": numericQualifier(parseFilterCount, func(idx indexInfo) float64 {
+		return float64(atoi(idx.priShards))
+	}),
+	"replicas": numericQualifier(parseFilterCount, func(idx indexInfo) float64 {
+		return float64(atoi(idx.replicas))
+	}),
+	"size": numericQualifier(parseFilterSize, func(idx indexInfo) float64 {
+		return float64(parseStoreSize(idx.storeSize))
+	}),
+}
+
+func qualifierNames() []string {
+	names := make([]string, 0, len(filterQualifiers))
+	for name := range filterQualifiers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// numericQualifier compares field with the number after the operator that
+// starts the value
+func numericQualifier(parse func(string) (float64, error), field func(indexInfo) float64) func(string) (func(indexInfo) bool, error) {
+	return func(value string) (func(indexInfo) bool, error) {
+		op := "="
+		for _, prefix := range []string{">=", "<=", ">", "<", "="} {
+			if strings.HasPrefix(value, prefix) {
+				op, value = prefix, value[len(prefix):]
+				break
+			}
+		}
+
+		n, err := parse(value)
+		if err != nil {
+			return nil, err
+		}
+
+		switch op {
+		case ">=":
+			return func(idx indexInfo) bool { return field(idx) >= n }, nil
+		case "<=":
+			return func(idx indexInfo) bool { return field(idx) <= n }, nil
+		case ">":
+			return func(idx indexInfo) bool { return field(idx) > n }, nil
+		case "<":
+			return func(idx indexInfo) bool { return field(idx) < n }, nil
+		default:
+			return func(idx indexInfo) bool { return field(idx) == n }, nil
+		}
+	}
+}
+
+// parseFilterCount reads a number, with an optional k or m suffix for
+// thousands and millions
+func parseFilterCount(value string) (float64, error) {
+	multiplier := 1.0
+	switch {
+	case strings.HasSuffix(value, "k"):
+		multiplier, value = 1e3, strings.TrimSuffix(value, "k")
+	case strings.HasSuffix(value, "m"):
+		multiplier, value = 1e6, strings.TrimSuffix(value, "m")
+	}
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a number", value)
+	}
+	return n * multiplier, nil
+}
+
+var filterSize = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?([kmgtp]?b?)$`)
+
+// parseFilterSize reads a size written like the _cat APIs do, such as 10gb
+func parseFilterSize(value string) (float64, error) {
+	value = strings.ToLower(value)
+	if !filterSize.MatchString(value) {
+		return 0, fmt.Errorf("%q is not a size such as 500mb or 10gb", value)
+	}
+	return float64(parseStoreSize(value)), nil
+}
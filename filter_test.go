@@ -0,0 +1,59 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestIndexFilter(t *testing.T) {
+	indices := []indexInfo{
+		{index: "logs-nginx-2026.10", health: "green", docs: 412_938_812, storeSize: "221.4gb", priShards: "3", replicas: "1", indexingRate: 1520.5},
+		{index: "orders-2026.10", health: "yellow", docs: 18_234_455, storeSize: "12.1gb", priShards: "3", replicas: "1", ingested: 1200},
+		{index: "products", health: "green", docs: 1_204_331, storeSize: "2.3gb", priShards: "1", replicas: "1"},
+		{index: ".security-7", health: "red", docs: 212, storeSize: "398.1kb", priShards: "1", replicas: "0"},
+	}
+
+	tests := []struct {
+		expr string
+		want []string
+	}{
+		{"", []string{"logs-nginx-2026.10", "orders-2026.10", "products", ".security-7"}},
+		{"2026", []string{"logs-nginx-2026.10", "orders-2026.10"}},
+		{"NGINX", []string{"logs-nginx-2026.10"}},
+		{"*-2026.??", []string{"logs-nginx-2026.10", "orders-2026.10"}},
+		{"prod*", []string{"products"}},
+		{"/^\\.", []string{".security-7"}},
+		{"/s$/", []string{"products"}},
+		{"health:red", []string{".security-7"}},
+		{"health:yellow,red", []string{"orders-2026.10", ".security-7"}},
+		{"rate:>0", []string{"logs-nginx-2026.10"}},
+		{"docs:>=18m", []string{"logs-nginx-2026.10", "orders-2026.10"}},
+		{"docs:<1k", []string{".security-7"}},
+		{"size:>10gb", []string{"logs-nginx-2026.10", "orders-2026.10"}},
+		{"shards:3 ingested:>0", []string{"orders-2026.10"}},
+		{"replicas:0", []string{".security-7"}},
+		{"2026 health:green", []string{"logs-nginx-2026.10"}},
+	}
+	for _, tt := range tests {
+		filter, err := parseIndexFilter(tt.expr)
+		if err != nil {
+			t.Errorf("parseIndexFilter(%q): %v", tt.expr, err)
+			continue
+		}
+		var got []string
+		for _, idx := range indices {
+			if filter.match(idx) {
+				got = append(got, idx.index)
+			}
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("filter %q matches %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+
+	for _, expr := range []string{"/(", "owner:me", "health:blue", "docs:>many", "size:>10xb"} {
+		if _, err := parseIndexFilter(expr); err == nil {
+			t.Errorf("parseIndexFilter(%q) succeeded, want an error", expr)
+		}
+	}
+}
@@ -0,0 +1,382 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rivo/tview"
+)
+
+// IndexDetail is everything the detail screen of an index shows
+type IndexDetail struct {
+	Name      string
+	Index     IndexStats             // The _cat/indices line of the index, empty if it failed
+	Settings  map[string]interface{} // Flat settings, e.g. index.number_of_shards
+	Fields    int                    // Mapped fields, counted like index.mapping.total_fields.limit does
+	Aliases   map[string]IndexAlias
+	ILM       *ILMExplain // nil when the distribution has no ILM
+	Shards    CatShards
+	Stats     IndexDetailStats
+	Primaries IndexDetailStats
+	FetchedAt time.Time
+	Errors    map[string]error
+}
+
+// IndexAlias is an alias pointing at the index
+type IndexAlias struct {
+	IsWriteIndex  *bool                  `json:"is_write_index"`
+	Filter        map[string]interface{} `json:"filter"`
+	IndexRouting  string                 `json:"index_routing"`
+	SearchRouting string                 `json:"search_routing"`
+}
+
+// ILMExplain is where the index is in its index lifecycle policy
+type ILMExplain struct {
+	Managed    bool   `json:"managed"`
+	Policy     string `json:"policy"`
+	Phase      string `json:"phase"`
+	Action     string `json:"action"`
+	Step       string `json:"step"`
+	Age        string `json:"age"`
+	FailedStep string `json:"failed_step"`
+	StepInfo   struct {
+		Reason string `json:"reason"`
+	} `json:"step_info"`
+}
+
+// CatShards is the response of /_cat/shards for the columns in catShardsColumns
+type CatShards []struct {
+	Shard            string `json:"shard"`
+	PriRep           string `json:"prirep"`
+	State            string `json:"state"`
+	Docs             string `json:"docs"`
+	Store            string `json:"store"`
+	Node             string `json:"node"`
+	Segments         string `json:"segments.count"`
+	UnassignedReason string `json:"unassigned.reason"`
+}
+
+const catShardsColumns = "shard,prirep,state,docs,store,node,segments.count,unassigned.reason"
+
+// IndexDetailStats are the statistics of /{index}/_stats, over either the
+// primaries or all copies of the shards
+type IndexDetailStats struct {
+	Docs struct {
+		Count   int64 `json:"count"`
+		Deleted int64 `json:"deleted"`
+	} `json:"docs"`
+	Store struct {
+		SizeInBytes int64 `json:"size_in_bytes"`
+	} `json:"store"`
+	Indexing struct {
+		IndexTotal        int64 `json:"index_total"`
+		IndexTimeInMillis int64 `json:"index_time_in_millis"`
+		IndexCurrent      int64 `json:"index_current"`
+		IndexFailed       int64 `json:"index_failed"`
+		DeleteTotal       int64 `json:"delete_total"`
+	} `json:"indexing"`
+	Search struct {
+		QueryTotal        int64 `json:"query_total"`
+		QueryTimeInMillis int64 `json:"query_time_in_millis"`
+		QueryCurrent      int64 `json:"query_current"`
+		FetchTotal        int64 `json:"fetch_total"`
+		FetchTimeInMillis int64 `json:"fetch_time_in_millis"`
+		ScrollTotal       int64 `json:"scroll_total"`
+	} `json:"search"`
+	Refresh struct {
+		Total             int64 `json:"total"`
+		TotalTimeInMillis int64 `json:"total_time_in_millis"`
+	} `json:"refresh"`
+	Merges struct {
+		Current            int64 `json:"current"`
+		Total              int64 `json:"total"`
+		TotalTimeInMillis  int64 `json:"total_time_in_millis"`
+		TotalSizeInBytes   int64 `json:"total_size_in_bytes"`
+		TotalThrottledTime int64 `json:"total_throttled_time_in_millis"`
+	} `json:"merges"`
+	Flush struct {
+		Total             int64 `json:"total"`
+		TotalTimeInMillis int64 `json:"total_time_in_millis"`
+	} `json:"flush"`
+	Segments struct {
+		Count int64 `json:"count"`
+	} `json:"segments"`
+}
+
+// indexMapping is a level of the mappings of an index
+type indexMapping struct {
+	Properties map[string]indexMapping `json:"properties"`
+	Fields     map[string]indexMapping `json:"fields"` // Multi-fields, such as a keyword under a text
+}
+
+// countFields counts the fields under m, objects and multi-fields included
+func countFields(m indexMapping) int {
+	n := 0
+	for _, child := range m.Properties {
+		n += 1 + countFields(child)
+	}
+	for _, child := range m.Fields {
+		n += 1 + countFields(child)
+	}
+	return n
+}
+
+// fetchIndexDetail fetches the detail of index
+func fetchIndexDetail(ctx context.Context, client *Client, dist Distribution, index string) *IndexDetail {
+	d := &IndexDetail{Name: index, Errors: make(map[string]error)}
+	escaped := url.PathEscape(index)
+
+	var (
+		settings map[string]struct {
+			Settings map[string]interface{} `json:"settings"`
+		}
+		mappings map[string]struct {
+			Mappings indexMapping `json:"mappings"`
+		}
+		aliases map[string]struct {
+			Aliases map[string]IndexAlias `json:"aliases"`
+		}
+		ilm struct {
+			Indices map[string]ILMExplain `json:"indices"`
+		}
+		stats struct {
+			Indices map[string]struct {
+				Primaries IndexDetailStats `json:"primaries"`
+				Total     IndexDetailStats `json:"total"`
+			} `json:"indices"`
+		}
+	)
+	requests := map[string]interface{}{
+		"/_cat/indices/" + escaped + "?format=json":                              &d.Index,
+		"/" + escaped + "/_settings?flat_settings=true":                          &settings,
+		"/" + escaped + "/_mapping":                                              &mappings,
+		"/" + escaped + "/_alias":                                                &aliases,
+		"/_cat/shards/" + escaped + "?format=json&bytes=b&h=" + catShardsColumns: &d.Shards,
+		"/" + escaped + "/_stats":                                                &stats,
+	}
+	// OpenSearch manages indices with its own ISM plugin instead
+	if dist.Name == distElasticsearch {
+		requests["/"+escaped+"/_ilm/explain"] = &ilm
+	}
+
+	fetchAll(ctx, client, requests, d.Errors)
+
+	d.Settings = settings[index].Settings
+	d.Fields = countFields(mappings[index].Mappings)
+	d.Aliases = aliases[index].Aliases
+	if explain, ok := ilm.Indices[index]; ok {
+		d.ILM = &explain
+	}
+	d.Stats = stats.Indices[index].Total
+	d.Primaries = stats.Indices[index].Primaries
+	d.FetchedAt = time.Now()
+	return d
+}
+
+// renderIndexDetail writes the detail screen of an index into view
+func renderIndexDetail(view *tview.TextView, d *IndexDetail) {
+	view.Clear()
+
+	fmt.Fprintf(view, "[::b][#00ffff]Index %s[::-]", tview.Escape(d.Name))
+	if len(d.Index) > 0 {
+		cat := d.Index[0]
+		fmt.Fprintf(view, " [%s]%s[white] [#666666](%s)[white]", getHealthColor(cat.Health), strings.ToUpper(cat.Health), cat.Status)
+	}
+	fmt.Fprintln(view)
+	detailStatus(view, d.FetchedAt, d.Errors)
+
+	detailSection(view, "Overview")
+	if len(d.Index) > 0 {
+		cat := d.Index[0]
+		docs := 0
+		fmt.Sscanf(cat.DocsCount, "%d", &docs)
+		detailRow(view, "Documents", "%s", formatNumber(docs))
+		detailRow(view, "Size", "%s [#444444](%s primaries)[white]", convertSizeFormat(cat.StoreSize), convertSizeFormat(cat.PriStoreSize))
+		detailRow(view, "Shards", "%s primaries with %s replicas each", cat.PriShards, cat.Replicas)
+	}
+	detailRow(view, "Mapped fields", "%s [#444444](limit %s)[white]", formatNumber(d.Fields), indexSetting(d.Settings, "index.mapping.total_fields.limit", "1000"))
+	if created, ok := d.Settings["index.creation_date"].(string); ok {
+		var millis int64
+		fmt.Sscanf(created, "%d", &millis)
+		at := time.UnixMilli(millis)
+		detailRow(view, "Created", "%s [#444444](%s ago)[white]", at.Format("2006-01-02 15:04"), strings.TrimSpace(formatUptime(d.FetchedAt.Sub(at).Milliseconds())))
+	}
+	fmt.Fprintln(view)
+
+	detailSection(view, "Aliases")
+	if len(d.Aliases) == 0 {
+		fmt.Fprintln(view, "  [#444444]none[white]")
+	}
+	names := make([]string, 0, len(d.Aliases))
+	for name := range d.Aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		alias := d.Aliases[name]
+		var notes []string
+		if alias.IsWriteIndex != nil && *alias.IsWriteIndex {
+			notes = append(notes, "write index")
+		}
+		if alias.Filter != nil {
+			notes = append(notes, "filtered")
+		}
+		if alias.IndexRouting != "" || alias.SearchRouting != "" {
+			notes = append(notes, "routed")
+		}
+		line := "  " + tview.Escape(name)
+		if len(notes) > 0 {
+			line += " [#444444](" + strings.Join(notes, ", ") + ")[white]"
+		}
+		fmt.Fprintln(view, line)
+	}
+	fmt.Fprintln(view)
+
+	detailSection(view, "Lifecycle")
+	switch {
+	case d.ILM == nil:
+		fmt.Fprintln(view, "  [#444444]unknown[white]")
+	case !d.ILM.Managed:
+		fmt.Fprintln(view, "  [#444444]not managed by ILM[white]")
+	default:
+		detailRow(view, "Policy", "%s", d.ILM.Policy)
+		detailRow(view, "Phase", "[#bd93f9]%s[white] [#444444](%s old)[white]", d.ILM.Phase, d.ILM.Age)
+		detailRow(view, "Action", "%s / %s", d.ILM.Action, d.ILM.Step)
+		if d.ILM.FailedStep != "" {
+			detailRow(view, "Failed step", "[#ff5555]%s[white] %s", d.ILM.FailedStep, tview.Escape(d.ILM.StepInfo.Reason))
+		}
+	}
+	fmt.Fprintln(view)
+
+	renderIndexShards(view, d.Shards)
+
+	detailSection(view, "Statistics")
+	fmt.Fprintf(view, "  [#00ffff]%-22s %20s %20s[white]\n", "", "Primaries", "Total")
+	both := func(name string, value func(IndexDetailStats) string) {
+		fmt.Fprintf(view, "  [#00ffff]%-22s[white] %20s %20s\n", name+":", value(d.Primaries), value(d.Stats))
+	}
+	both("Documents", func(s IndexDetailStats) string { return formatCount(s.Docs.Count) })
+	both("Deleted documents", func(s IndexDetailStats) string { return formatCount(s.Docs.Deleted) })
+	both("Store size", func(s IndexDetailStats) string { return bytesToHuman(s.Store.SizeInBytes) })
+	both("Segments", func(s IndexDetailStats) string { return formatCount(s.Segments.Count) })
+	both("Indexed", func(s IndexDetailStats) string { return formatCount(s.Indexing.IndexTotal) })
+	both("Indexing time", func(s IndexDetailStats) string {
+		return formatLatency(s.Indexing.IndexTimeInMillis, s.Indexing.IndexTotal)
+	})
+	both("Indexing failed", func(s IndexDetailStats) string { return formatCount(s.Indexing.IndexFailed) })
+	both("Deleted", func(s IndexDetailStats) string { return formatCount(s.Indexing.DeleteTotal) })
+	both("Queries", func(s IndexDetailStats) string { return formatCount(s.Search.QueryTotal) })
+	both("Query time", func(s IndexDetailStats) string {
+		return formatLatency(s.Search.QueryTimeInMillis, s.Search.QueryTotal)
+	})
+	both("Fetches", func(s IndexDetailStats) string { return formatCount(s.Search.FetchTotal) })
+	both("Scrolls", func(s IndexDetailStats) string { return formatCount(s.Search.ScrollTotal) })
+	both("Refreshes", func(s IndexDetailStats) string { return formatCount(s.Refresh.Total) })
+	both("Refresh time", func(s IndexDetailStats) string {
+		return formatLatency(s.Refresh.TotalTimeInMillis, s.Refresh.Total)
+	})
+	both("Merges", func(s IndexDetailStats) string {
+		return fmt.Sprintf("%s (%d now)", formatCount(s.Merges.Total), s.Merges.Current)
+	})
+	both("Merged", func(s IndexDetailStats) string { return bytesToHuman(s.Merges.TotalSizeInBytes) })
+	both("Merge time", func(s IndexDetailStats) string {
+		return formatLatency(s.Merges.TotalTimeInMillis, s.Merges.Total)
+	})
+	both("Flushes", func(s IndexDetailStats) string { return formatCount(s.Flush.Total) })
+	fmt.Fprintln(view)
+
+	detailSection(view, "Settings")
+	keys := make([]string, 0, len(d.Settings))
+	for key := range d.Settings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(view, "  [#00ffff]%s[white] = %s\n", key, tview.Escape(fmt.Sprint(d.Settings[key])))
+	}
+}
+
+// renderIndexShards writes the shard list of an index, primaries first
+func renderIndexShards(view *tview.TextView, shards CatShards) {
+	sort.SliceStable(shards, func(i, j int) bool {
+		if a, b := atoi(shards[i].Shard), atoi(shards[j].Shard); a != b {
+			return a < b
+		}
+		return shards[i].PriRep == "p" && shards[j].PriRep != "p"
+	})
+
+	detailSection(view, "Shards")
+	fmt.Fprintf(view, "  [#00ffff]%5s  %-7s  %-12s %13s %6s %8s  %s[white]\n", "Shard", "Copy", "State", "Documents", "Size", "Segments", "Node")
+	for _, shard := range shards {
+		kind := "replica"
+		if shard.PriRep == "p" {
+			kind = "primary"
+		}
+		node := tview.Escape(shard.Node)
+		if shard.State == "UNASSIGNED" {
+			node = "[#444444]" + shard.UnassignedReason + "[white]"
+		}
+		docs, size := formatShardSize(shard.Docs, shard.Store)
+		fmt.Fprintf(view, "  %5s  %-7s  %s %13s %6s %8s  %s\n",
+			shard.Shard, kind, formatShardState(shard.State, 12), docs, size, shard.Segments, node)
+	}
+	fmt.Fprintln(view)
+}
+
+// formatShardState colors the state of a shard, padded to width
+func formatShardState(state string, width int) string {
+	padding := strings.Repeat(" ", max(width-len(state), 0))
+	switch state {
+	case "STARTED":
+		return "[green]" + state + "[white]" + padding
+	case "UNASSIGNED":
+		return "[#ff5555]" + state + "[white]" + padding
+	default:
+		return "[#ffff00]" + state + "[white]" + padding
+	}
+}
+
+// formatShardSize formats the documents and bytes of a shard from
+// /_cat/shards, both empty when the shard is not allocated
+func formatShardSize(docs, store string) (string, string) {
+	size := ""
+	if store != "" {
+		var bytes int64
+		fmt.Sscanf(store, "%d", &bytes)
+		size = formatResourceSize(bytes)
+	}
+	if docs != "" {
+		docs = formatNumber(atoi(docs))
+	}
+	return docs, size
+}
+
+// indexSetting returns a flat setting as text, def when it is not set
+func indexSetting(settings map[string]interface{}, key, def string) string {
+	if value, ok := settings[key]; ok {
+		return fmt.Sprint(value)
+	}
+	return def
+}
+
+// formatLatency renders a total time in milliseconds along with the average
+// per operation
+func formatLatency(totalMillis, count int64) string {
+	total := (time.Duration(totalMillis) * time.Millisecond).Round(time.Second)
+	if count == 0 {
+		return total.String()
+	}
+	return fmt.Sprintf("%s (%.1fms)", total, float64(totalMillis)/float64(count))
+}
+
+// openIndexDetail opens the detail screen of index
+func (a *App) openIndexDetail(index string) {
+	a.openDetail("Index", func(ctx context.Context, client *Client, dist Distribution) func(*tview.TextView) {
+		d := fetchIndexDetail(ctx, client, dist, index)
+		return func(view *tview.TextView) { renderIndexDetail(view, d) }
+	})
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/rivo/tview"
+)
+
+func TestRenderIndexDetail(t *testing.T) {
+	srv := newFakeCluster(t)
+	client, err := NewClient(ClientConfig{URLs: []string{srv.URL}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := fetchIndexDetail(context.Background(), client, defaultDistribution, "orders-2026.10")
+	for path, err := range d.Errors {
+		t.Errorf("%s: %v", path, err)
+	}
+	if d.Fields != 20 {
+		t.Errorf("counted %d mapped fields, want 20", d.Fields)
+	}
+
+	// Times end up on screen, relative to when the detail was fetched
+	d.FetchedAt = fakeSnapshotTime
+	d.Settings["index.creation_date"] = fmt.Sprint(fakeSnapshotTime.AddDate(0, 0, -15).UnixMilli())
+
+	view := tview.NewTextView().SetDynamicColors(true)
+	renderIndexDetail(view, d)
+	checkGolden(t, "index-detail", screenText(t, view, 100, 60))
+}
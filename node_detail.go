@@ -0,0 +1,336 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rivo/tview"
+)
+
+// NodeDetail is everything the detail screen of a node shows
+type NodeDetail struct {
+	ID        string
+	Info      NodeInfo    // Empty if it failed or the node left the cluster
+	Stats     NodeStats   // Likewise
+	Shards    []NodeShard // The shard copies on the node, moving ones included
+	FetchedAt time.Time
+	Errors    map[string]error
+
+	// NodeNames are the names of the nodes by ID, from the poll on screen,
+	// telling where shards move to or from
+	NodeNames map[string]string
+}
+
+// NodeShardStats is the response of /_nodes/{id}/stats/indices at shard
+// level: the copies on the node by index, each in an object of its own
+// keyed by shard number
+type NodeShardStats struct {
+	Nodes map[string]struct {
+		Indices struct {
+			Shards map[string][]map[string]NodeShard `json:"shards"`
+		} `json:"indices"`
+	} `json:"nodes"`
+}
+
+// NodeShard is a shard copy in the node stats. Index and Shard are taken
+// from the keys it is listed under.
+type NodeShard struct {
+	Index   string `json:"-"`
+	Shard   int    `json:"-"`
+	Routing struct {
+		State          string `json:"state"`
+		Primary        bool   `json:"primary"`
+		RelocatingNode string `json:"relocating_node"`
+	} `json:"routing"`
+	Docs struct {
+		Count int64 `json:"count"`
+	} `json:"docs"`
+	Store struct {
+		SizeInBytes int64 `json:"size_in_bytes"`
+	} `json:"store"`
+	Segments struct {
+		Count int64 `json:"count"`
+	} `json:"segments"`
+}
+
+// fetchNodeDetail fetches the detail of the node with id
+func fetchNodeDetail(ctx context.Context, client *Client, id string) *NodeDetail {
+	d := &NodeDetail{ID: id, Errors: make(map[string]error)}
+	escaped := url.PathEscape(id)
+
+	var (
+		info   NodesInfo
+		stats  NodesStats
+		shards NodeShardStats
+	)
+	// The shards come from the node's own stats rather than /_cat/shards,
+	// which lists every shard of the cluster, and only with the metrics shown
+	fetchAll(ctx, client, map[string]interface{}{
+		"/_nodes/" + escaped:            &info,
+		"/_nodes/" + escaped + "/stats": &stats,
+		"/_nodes/" + escaped + "/stats/indices/docs,store,segments?level=shards": &shards,
+	}, d.Errors)
+
+	d.Info = info.Nodes[id]
+	d.Stats = stats.Nodes[id]
+	for index, copies := range shards.Nodes[id].Indices.Shards {
+		for _, byNumber := range copies {
+			for number, shard := range byNumber {
+				shard.Index, shard.Shard = index, atoi(number)
+				d.Shards = append(d.Shards, shard)
+			}
+		}
+	}
+	d.FetchedAt = time.Now()
+	return d
+}
+
+// renderNodeDetail writes the detail screen of a node into view
+func renderNodeDetail(view *tview.TextView, d *NodeDetail) {
+	view.Clear()
+	info, stats := d.Info, d.Stats
+
+	name := info.Name
+	if name == "" {
+		name = d.ID
+	}
+	fmt.Fprintf(view, "[::b][#00ffff]Node %s[::-] [white]%s [#666666](%s)[white]", tview.Escape(name), info.Version, d.ID)
+	fmt.Fprintln(view)
+	detailStatus(view, d.FetchedAt, d.Errors)
+	header := func(format string, args ...interface{}) {
+		fmt.Fprintf(view, "  [#00ffff]"+format+"[white]\n", args...)
+	}
+
+	detailSection(view, "Overview")
+	if len(info.Roles) > 0 {
+		detailRow(view, "Roles", "%s", strings.Join(info.Roles, ", "))
+	}
+	if info.Host != "" {
+		detailRow(view, "Host", "%s [#444444](transport %s, HTTP %s)[white]", info.Host, info.TransportAddress, info.HTTP.PublishAddress)
+	}
+	if info.OS.PrettyName != "" {
+		detailRow(view, "OS", "%s [#444444](%s, %d processors)[white]", info.OS.PrettyName, info.OS.Arch, info.OS.AvailableProcessors)
+	}
+	if info.JVM.Version != "" {
+		detailRow(view, "JVM", "%s %s [#444444](%s)[white]", info.JVM.VMName, info.JVM.Version, info.JVM.VMVendor)
+	}
+	detailRow(view, "Uptime", "%s", strings.TrimSpace(formatUptime(stats.JVM.UptimeInMillis)))
+	detailRow(view, "CPU", "[%s]%d%%[white], load %s", getPercentageColor(float64(stats.OS.CPU.Percent)), stats.OS.CPU.Percent,
+		strings.TrimSpace(formatLoadAverage(stats.OS.LoadAverage, info.OS.AvailableProcessors)))
+	detailRow(view, "Memory", "%s of %s %s", bytesToHuman(stats.OS.Memory.UsedInBytes), bytesToHuman(stats.OS.Memory.TotalInBytes),
+		formatUsedPercent(stats.OS.Memory.UsedInBytes, stats.OS.Memory.TotalInBytes))
+	detailRow(view, "File descriptors", "%s of %s", formatCount(stats.Process.OpenFileDescriptors), formatCount(stats.Process.MaxFileDescriptors))
+	fmt.Fprintln(view)
+
+	detailSection(view, "Attributes")
+	if len(info.Attributes) == 0 {
+		fmt.Fprintln(view, "  [#444444]none[white]")
+	}
+	keys := make([]string, 0, len(info.Attributes))
+	for key := range info.Attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(view, "  [#00ffff]%s[white] = %s\n", tview.Escape(key), tview.Escape(info.Attributes[key]))
+	}
+	fmt.Fprintln(view)
+
+	detailSection(view, "Plugins")
+	if len(info.Plugins) == 0 {
+		fmt.Fprintln(view, "  [#444444]none[white]")
+	}
+	for _, plugin := range info.Plugins {
+		fmt.Fprintf(view, "  %s [#444444]%s[white]\n", tview.Escape(plugin.Name), plugin.Version)
+	}
+	if len(info.Modules) > 0 {
+		fmt.Fprintf(view, "  [#444444]and %d modules[white]\n", len(info.Modules))
+	}
+	fmt.Fprintln(view)
+
+	renderNodeThreadPools(view, stats.ThreadPool)
+
+	detailSection(view, "Circuit breakers")
+	header("%-20s %10s %10s %5s %9s %8s", "Breaker", "Estimated", "Limit", "Used", "Overhead", "Tripped")
+	breakers := make([]string, 0, len(stats.Breakers))
+	for name := range stats.Breakers {
+		breakers = append(breakers, name)
+	}
+	sort.Strings(breakers)
+	for _, name := range breakers {
+		b := stats.Breakers[name]
+		tripped := formatCount(b.Tripped)
+		if b.Tripped > 0 {
+			tripped = "[#ff5555]" + tripped + "[white]"
+		}
+		fmt.Fprintf(view, "  %-20s %10s %10s  %s %9.2f %s%s\n", name,
+			bytesToHuman(b.EstimatedSizeInBytes), bytesToHuman(b.LimitSizeInBytes),
+			formatUsedPercent(b.EstimatedSizeInBytes, b.LimitSizeInBytes), b.Overhead,
+			strings.Repeat(" ", max(8-len(formatCount(b.Tripped)), 0)), tripped)
+	}
+	fmt.Fprintln(view)
+
+	detailSection(view, "File system")
+	header("%-32s %-6s %10s %10s %10s %5s", "Path", "Type", "Used", "Available", "Total", "Used")
+	for _, data := range stats.FS.Data {
+		used := data.TotalInBytes - data.AvailableInBytes
+		fmt.Fprintf(view, "  %-32s %-6s %10s %10s %10s  %s\n", tview.Escape(data.Path), data.Type,
+			bytesToHuman(used), bytesToHuman(data.AvailableInBytes), bytesToHuman(data.TotalInBytes), formatUsedPercent(used, data.TotalInBytes))
+		if data.Mount != "" && !strings.HasPrefix(data.Mount, data.Path+" ") {
+			fmt.Fprintf(view, "    [#444444]on %s[white]\n", tview.Escape(data.Mount))
+		}
+	}
+	fmt.Fprintln(view)
+
+	detailSection(view, "JVM")
+	mem := stats.JVM.Memory
+	detailRow(view, "Heap", "%s of %s %s [#444444](%s committed)[white]", bytesToHuman(mem.HeapUsedInBytes), bytesToHuman(mem.HeapMaxInBytes),
+		formatUsedPercent(mem.HeapUsedInBytes, mem.HeapMaxInBytes), bytesToHuman(mem.HeapCommittedInBytes))
+	detailRow(view, "Non-heap", "%s [#444444](%s committed)[white]", bytesToHuman(mem.NonHeapUsedInBytes), bytesToHuman(mem.NonHeapCommittedInBytes))
+	detailRow(view, "Threads", "%s [#444444](peak %s)[white]", formatCount(stats.JVM.Threads.Count), formatCount(stats.JVM.Threads.PeakCount))
+	fmt.Fprintln(view)
+	header("%-20s %10s %10s %10s", "Memory pool", "Used", "Max", "Peak")
+	pools := make([]string, 0, len(mem.Pools))
+	for name := range mem.Pools {
+		pools = append(pools, name)
+	}
+	sort.Strings(pools)
+	for _, name := range pools {
+		pool := mem.Pools[name]
+		// Pools without a maximum of their own share the heap
+		limit := "heap"
+		if pool.MaxInBytes > 0 {
+			limit = bytesToHuman(pool.MaxInBytes)
+		}
+		fmt.Fprintf(view, "  %-20s %10s %10s %10s\n", name, bytesToHuman(pool.UsedInBytes), limit, bytesToHuman(pool.PeakUsedInBytes))
+	}
+	fmt.Fprintln(view)
+	header("%-20s %12s  %s", "GC collector", "Collections", "Time")
+	collectors := stats.JVM.GC.Collectors
+	fmt.Fprintf(view, "  %-20s %12s  %s\n", "young", formatCount(collectors.Young.CollectionCount),
+		formatLatency(collectors.Young.CollectionTimeInMillis, collectors.Young.CollectionCount))
+	fmt.Fprintf(view, "  %-20s %12s  %s\n", "old", formatCount(collectors.Old.CollectionCount),
+		formatLatency(collectors.Old.CollectionTimeInMillis, collectors.Old.CollectionCount))
+	fmt.Fprintln(view)
+
+	detailSection(view, "Network")
+	transport := stats.Transport
+	detailRow(view, "Transport connections", "%s inbound, %s outbound", formatCount(transport.ServerOpen), formatCount(transport.TotalOutboundConnections))
+	detailRow(view, "Transport received", "%s in %s messages", bytesToHuman(transport.RxSizeInBytes), formatCount(transport.RxCount))
+	detailRow(view, "Transport sent", "%s in %s messages", bytesToHuman(transport.TxSizeInBytes), formatCount(transport.TxCount))
+	detailRow(view, "HTTP connections", "%s open [#444444](%s opened)[white]", formatCount(stats.HTTP.CurrentOpen), formatCount(stats.HTTP.TotalOpened))
+	fmt.Fprintln(view)
+
+	renderNodeShards(view, d.Shards, d.NodeNames)
+}
+
+// renderNodeThreadPools writes the thread pools of a node that ever ran a
+// task, the others would only fill the screen with zeros
+func renderNodeThreadPools(view *tview.TextView, pools map[string]ThreadPoolStats) {
+	names := make([]string, 0, len(pools))
+	idle := 0
+	for name, pool := range pools {
+		if pool.Largest == 0 && pool.Queue == 0 && pool.Rejected == 0 {
+			idle++
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	detailSection(view, "Thread pools")
+	fmt.Fprintf(view, "  [#00ffff]%-20s %7s %7s %7s %7s %9s %14s[white]\n", "Pool", "Threads", "Active", "Largest", "Queue", "Rejected", "Completed")
+	for _, name := range names {
+		pool := pools[name]
+		queue := fmt.Sprintf("%7d", pool.Queue)
+		if pool.Queue > 0 {
+			queue = "[#ffff00]" + queue + "[white]"
+		}
+		rejected := fmt.Sprintf("%9s", formatNumber(int(pool.Rejected)))
+		if pool.Rejected > 0 {
+			rejected = "[#ff5555]" + rejected + "[white]"
+		}
+		fmt.Fprintf(view, "  %-20s %7d %7d %7d %s %s %14s\n", name,
+			pool.Threads, pool.Active, pool.Largest, queue, rejected, formatNumber(int(pool.Completed)))
+	}
+	if idle > 0 {
+		fmt.Fprintf(view, "  [#444444]and %d idle pools[white]\n", idle)
+	}
+	fmt.Fprintln(view)
+}
+
+// renderNodeShards writes the shard copies on the node, by index. Moving
+// copies name the node at the other end as found in names.
+func renderNodeShards(view *tview.TextView, shards []NodeShard, names map[string]string) {
+	sort.Slice(shards, func(i, j int) bool {
+		if shards[i].Index != shards[j].Index {
+			return shards[i].Index < shards[j].Index
+		}
+		if shards[i].Shard != shards[j].Shard {
+			return shards[i].Shard < shards[j].Shard
+		}
+		return shards[i].Routing.Primary && !shards[j].Routing.Primary
+	})
+
+	primaries := 0
+	for _, shard := range shards {
+		if shard.Routing.Primary {
+			primaries++
+		}
+	}
+	fmt.Fprintf(view, "[::b][#00ffff]Shards[::-] [#444444](%d, %d primaries)[white]\n", len(shards), primaries)
+	width := len("Index")
+	for _, shard := range shards {
+		width = max(width, len(shard.Index))
+	}
+	fmt.Fprintf(view, "  [#00ffff]%-*s %5s  %-7s  %-12s %11s %6s %8s[white]\n", width, "Index", "Shard", "Copy", "State", "Documents", "Size", "Segments")
+	for _, shard := range shards {
+		kind := "replica"
+		if shard.Routing.Primary {
+			kind = "primary"
+		}
+		// The source of a move is RELOCATING, the target INITIALIZING
+		note := ""
+		if other := shard.Routing.RelocatingNode; other != "" {
+			if name, ok := names[other]; ok {
+				other = name
+			}
+			if shard.Routing.State == "RELOCATING" {
+				note = "  [#444444]to " + tview.Escape(other) + "[white]"
+			} else {
+				note = "  [#444444]from " + tview.Escape(other) + "[white]"
+			}
+		}
+		fmt.Fprintf(view, "  %-*s %5d  %-7s  %s %11s %6s %8d%s\n",
+			width, tview.Escape(shard.Index), shard.Shard, kind, formatShardState(shard.Routing.State, 12),
+			formatCount(shard.Docs.Count), formatResourceSize(shard.Store.SizeInBytes), shard.Segments.Count, note)
+	}
+}
+
+// formatUsedPercent colors the share of total that is used, "" without a total
+func formatUsedPercent(used, total int64) string {
+	if total <= 0 {
+		return ""
+	}
+	p := percent(used, total)
+	return fmt.Sprintf("[%s]%3.0f%%[white]", getPercentageColor(p), p)
+}
+
+// openNodeDetail opens the detail screen of the node with id
+func (a *App) openNodeDetail(id string) {
+	names := make(map[string]string)
+	if a.shown != nil && a.shown.snap != nil {
+		for nodeID, node := range a.shown.snap.NodesInfo.Nodes {
+			names[nodeID] = node.Name
+		}
+	}
+	a.openDetail("Node", func(ctx context.Context, client *Client, dist Distribution) func(*tview.TextView) {
+		d := fetchNodeDetail(ctx, client, id)
+		d.NodeNames = names
+		return func(view *tview.TextView) { renderNodeDetail(view, d) }
+	})
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rivo/tview"
+)
+
+func TestRenderNodeDetail(t *testing.T) {
+	srv := newFakeCluster(t)
+	client, err := NewClient(ClientConfig{URLs: []string{srv.URL}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := fetchNodeDetail(context.Background(), client, "hT2qXdG6R8a9Jk3LmN4oPw")
+	for path, err := range d.Errors {
+		t.Errorf("%s: %v", path, err)
+	}
+	// The shards of es-hot-1, the one moving to it included
+	if len(d.Shards) != 16 {
+		t.Errorf("picked %d shards of the node, want 16", len(d.Shards))
+	}
+
+	d.FetchedAt = fakeSnapshotTime
+	d.NodeNames = map[string]string{"pL7sYe3QT0mB5nV6cX8zDq": "es-warm-1"}
+	view := tview.NewTextView().SetDynamicColors(true)
+	renderNodeDetail(view, d)
+	checkGolden(t, "node-detail", screenText(t, view, 120, 110))
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// outputFormats are the formats -once can print
+var outputFormats = []string{"json"}
+
+func checkFormat(format string) error {
+	for _, f := range outputFormats {
+		if f == format {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown output format %q (want one of %v)", format, outputFormats)
+}
+
+// runOnce collects every cluster twice, interval apart so that rates can be
+// derived, and writes the reports to w. A single cluster is written as one
+// object, several as an object keyed by cluster name.
+func runOnce(w io.Writer, settings []Settings, collectors []*Collector, interval time.Duration) error {
+	if err := checkFormat(settings[0].Format); err != nil {
+		return err
+	}
+
+	named := func(i int, err error) error {
+		if len(settings) > 1 {
+			return fmt.Errorf("%s: %w", settings[i].Name, err)
+		}
+		return err
+	}
+
+	// The first sample is the baseline for rates and ingested documents
+	first := make([]*Snapshot, len(collectors))
+	activities := make([]map[string]*IndexActivity, len(collectors))
+	for i, c := range collectors {
+		snap, err := c.collect(pollTimeout)
+		if err != nil {
+			return named(i, err)
+		}
+		first[i] = snap
+		activities[i] = make(map[string]*IndexActivity)
+		trackIndexActivity(activities[i], snap.IndicesStats)
+	}
+
+	time.Sleep(interval)
+
+	reports := make(map[string]Report, len(collectors))
+	for i, c := range collectors {
+		snap, err := c.collect(pollTimeout)
+		if err != nil {
+			return named(i, err)
+		}
+		trackIndexActivity(activities[i], snap.IndicesStats)
+
+		// Two samples give every window the same rate, which is really
+		// the rate over the time between them
+		report := buildReport(snap, settings[i].RateWindow, activities[i])
+		report.Name = settings[i].Name
+		report.RateWindow = formatDuration(snap.FetchedAt.Sub(first[i].FetchedAt).Round(time.Second))
+		reports[settings[i].Name] = report
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if len(settings) == 1 {
+		return enc.Encode(reports[settings[0].Name])
+	}
+	return enc.Encode(reports)
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// Failed nodes are skipped for a backoff that doubles with every failure in
+// a row, so a node that is down for a while is not hammered on every poll
+const (
+	deadBackoffMin = time.Second
+	deadBackoffMax = time.Minute
+)
+
+// sniffInterval is how often the node list is refreshed when sniffing
+const sniffInterval = 5 * time.Minute
+
+// poolNode is one HTTP endpoint of the cluster
+type poolNode struct {
+	url       string
+	failures  int // Failures in a row, 0 while the node is healthy
+	deadUntil time.Time
+}
+
+func (n *poolNode) alive(now time.Time) bool {
+	return n.failures == 0 || now.After(n.deadUntil)
+}
+
+// nodePool holds the endpoints requests can be sent to. Requests stick to one
+// node until it fails and then move on to the next live one, so a rolling
+// restart only costs the requests that were in flight on the restarting node.
+type nodePool struct {
+	mu        sync.Mutex
+	seeds     []string
+	nodes     []*poolNode
+	current   int
+	sniffedAt time.Time
+}
+
+func newNodePool(seeds []string) *nodePool {
+	p := &nodePool{seeds: seeds}
+	p.replace(nil)
+	return p
+}
+
+// pick returns the node to send a request to, skipping those in tried. When
+// every remaining node is dead the one closest to being retried is used, so
+// requests never stop entirely. It returns nil once every node was tried.
+func (p *nodePool) pick(tried map[*poolNode]bool) *poolNode {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	var fallback *poolNode
+	for i := range p.nodes {
+		n := p.nodes[(p.current+i)%len(p.nodes)]
+		if tried[n] {
+			continue
+		}
+		if n.alive(now) {
+			return n
+		}
+		if fallback == nil || n.deadUntil.Before(fallback.deadUntil) {
+			fallback = n
+		}
+	}
+	return fallback
+}
+
+// markAlive records a successful request to n and makes it the node to use
+func (p *nodePool) markAlive(n *poolNode) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	n.failures = 0
+	for i, node := range p.nodes {
+		if node == n {
+			p.current = i
+		}
+	}
+}
+
+// markDead takes n out of rotation for a backoff
+func (p *nodePool) markDead(n *poolNode) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	backoff := deadBackoffMin << n.failures
+	if backoff > deadBackoffMax || backoff <= 0 {
+		backoff = deadBackoffMax
+	}
+	n.failures++
+	n.deadUntil = time.Now().Add(backoff)
+}
+
+// Current returns the URL of the node requests are sent to
+func (p *nodePool) Current() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.nodes[p.current].url
+}
+
+// claimSniff reports whether the node list is due to be sniffed again and
+// if so records the attempt, so a failing sniff is not retried every poll
+func (p *nodePool) claimSniff() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if time.Since(p.sniffedAt) < sniffInterval {
+		return false
+	}
+	p.sniffedAt = time.Now()
+	return true
+}
+
+// replace sets the nodes to the sniffed urls followed by the seeds, which
+// are kept as a way back into the cluster should every sniffed node go away.
+// Nodes that were already known keep their health and the current node
+// stays current.
+func (p *nodePool) replace(urls []string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	known := make(map[string]*poolNode, len(p.nodes))
+	for _, n := range p.nodes {
+		known[n.url] = n
+	}
+	current := ""
+	if len(p.nodes) > 0 {
+		current = p.nodes[p.current].url
+	}
+
+	seen := make(map[string]bool)
+	p.nodes, p.current = nil, 0
+	for _, url := range append(urls, p.seeds...) {
+		url = strings.TrimRight(url, "/")
+		if seen[url] {
+			continue
+		}
+		seen[url] = true
+
+		n, ok := known[url]
+		if !ok {
+			n = &poolNode{url: url}
+		}
+		if url == current {
+			p.current = len(p.nodes)
+		}
+		p.nodes = append(p.nodes, n)
+	}
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+// poolURLs lists the nodes of p in order
+func poolURLs(p *nodePool) []string {
+	var urls []string
+	for _, n := range p.nodes {
+		urls = append(urls, n.url)
+	}
+	return urls
+}
+
+func TestNodePoolPick(t *testing.T) {
+	tests := []struct {
+		name  string
+		dead  []int // Nodes marked dead, in order
+		tried []int
+		want  int // -1 for none
+	}{
+		{name: "healthy", want: 0},
+		{name: "first dead", dead: []int{0}, want: 1},
+		{name: "tried are skipped", tried: []int{0, 1}, want: 2},
+		{name: "every node tried", tried: []int{0, 1, 2}, want: -1},
+		{name: "all dead, soonest retried first", dead: []int{1, 2, 0}, want: 1},
+		{name: "all dead but one tried", dead: []int{1, 2, 0}, tried: []int{1}, want: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newNodePool([]string{"http://a:9200", "http://b:9200", "http://c:9200"})
+			for _, i := range tt.dead {
+				p.markDead(p.nodes[i])
+				// Keep the backoffs of the dead nodes apart
+				time.Sleep(time.Millisecond)
+			}
+			tried := make(map[*poolNode]bool)
+			for _, i := range tt.tried {
+				tried[p.nodes[i]] = true
+			}
+
+			got := p.pick(tried)
+			switch {
+			case tt.want < 0 && got != nil:
+				t.Errorf("picked %s, want none", got.url)
+			case tt.want >= 0 && got != p.nodes[tt.want]:
+				t.Errorf("picked %v, want %s", got, p.nodes[tt.want].url)
+			}
+		})
+	}
+}
+
+func TestNodePoolBackoff(t *testing.T) {
+	p := newNodePool([]string{"http://a:9200"})
+	n := p.nodes[0]
+
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{3, 4 * time.Second},
+		{7, time.Minute},
+		{100, time.Minute},
+	}
+	for _, tt := range tests {
+		for n.failures < tt.failures {
+			p.markDead(n)
+		}
+		// The backoff starts when the node is marked dead
+		got := time.Until(n.deadUntil).Round(time.Second)
+		if got != tt.want {
+			t.Errorf("backoff after %d failures = %s, want %s", tt.failures, got, tt.want)
+		}
+	}
+
+	p.markAlive(n)
+	if !n.alive(time.Now()) {
+		t.Error("a node marked alive must be picked again right away")
+	}
+}
+
+func TestNodePoolReplace(t *testing.T) {
+	p := newNodePool([]string{"http://seed:9200/"})
+	if got, want := poolURLs(p), []string{"http://seed:9200"}; !slices.Equal(got, want) {
+		t.Fatalf("nodes of a new pool = %v, want the seeds %v", got, want)
+	}
+
+	p.replace([]string{"http://a:9200", "http://b:9200"})
+	b := p.nodes[1]
+	p.markDead(b)
+	p.markAlive(p.nodes[0])
+	p.markAlive(b)
+	p.markDead(b)
+
+	// The seeds stay last, known nodes keep their health and the current one
+	// stays current
+	p.replace([]string{"http://b:9200", "http://c:9200", "http://seed:9200"})
+	if got, want := poolURLs(p), []string{"http://b:9200", "http://c:9200", "http://seed:9200"}; !slices.Equal(got, want) {
+		t.Errorf("nodes after a sniff = %v, want %v", got, want)
+	}
+	if p.nodes[0] != b || b.failures != 1 {
+		t.Errorf("node b after a sniff = %+v, want the same node with its failure", p.nodes[0])
+	}
+	if got := p.Current(); got != "http://b:9200" {
+		t.Errorf("current node after a sniff = %s, want b", got)
+	}
+
+	// The current node leaving moves on to the first one
+	p.replace(nil)
+	if got := p.Current(); got != "http://seed:9200" {
+		t.Errorf("current node after every sniffed node left = %s, want the seed", got)
+	}
+}
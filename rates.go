@@ -0,0 +1,239 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// defaultRateWindows are the windows rates can be averaged over, cycled with 'w'
+var defaultRateWindows = []time.Duration{5 * time.Second, time.Minute, 5 * time.Minute}
+
+// counterSample is the value of a monotonic counter at a point in time
+type counterSample struct {
+	at    time.Time
+	value int64
+}
+
+// ring is a fixed size buffer that overwrites its oldest item once full
+type ring[T any] struct {
+	items []T
+	start int
+	size  int
+}
+
+func newRing[T any](capacity int) *ring[T] {
+	return &ring[T]{items: make([]T, capacity)}
+}
+
+func (r *ring[T]) Len() int {
+	return r.size
+}
+
+// At returns the i-th item, 0 being the oldest
+func (r *ring[T]) At(i int) T {
+	return r.items[(r.start+i)%len(r.items)]
+}
+
+func (r *ring[T]) Add(item T) {
+	if r.size < len(r.items) {
+		r.items[(r.start+r.size)%len(r.items)] = item
+		r.size++
+		return
+	}
+	r.items[r.start] = item
+	r.start = (r.start + 1) % len(r.items)
+}
+
+func (r *ring[T]) Reset() {
+	r.start, r.size = 0, 0
+}
+
+// Slice returns a copy of the items, oldest first
+func (r *ring[T]) Slice() []T {
+	items := make([]T, r.size)
+	for i := range items {
+		items[i] = r.At(i)
+	}
+	return items
+}
+
+// sampleRing keeps the most recent samples of a single counter
+type sampleRing struct {
+	*ring[counterSample]
+}
+
+func newSampleRing(capacity int) *sampleRing {
+	return &sampleRing{newRing[counterSample](capacity)}
+}
+
+func (r *sampleRing) Add(s counterSample) {
+	// A counter going backwards means the node restarted or the index was
+	// recreated, so the old samples no longer describe the same series
+	if r.size > 0 && s.value < r.At(r.size-1).value {
+		r.Reset()
+	}
+	r.ring.Add(s)
+}
+
+// Rate returns the per-second increase between the newest sample and the
+// newest one that is at least window older. With less history than that it
+// falls back to the oldest sample, and with fewer than two samples it is 0.
+func (r *sampleRing) Rate(window time.Duration) float64 {
+	if r.size < 2 {
+		return 0
+	}
+
+	last := r.At(r.size - 1)
+	first := r.At(0)
+	for i := r.size - 2; i >= 0; i-- {
+		if s := r.At(i); last.at.Sub(s.at) >= window {
+			first = s
+			break
+		}
+	}
+
+	elapsed := last.at.Sub(first.at).Seconds()
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(last.value-first.value) / elapsed
+}
+
+// RateEngine turns monotonic counters from successive polls into per-second
+// rates. Each counter is tracked under its own key in a ring sized to cover
+// the widest window at a one second refresh.
+type RateEngine struct {
+	capacity int
+	series   map[string]*sampleRing
+}
+
+func NewRateEngine(maxWindow time.Duration) *RateEngine {
+	return &RateEngine{
+		capacity: int(maxWindow/time.Second) + 1,
+		series:   make(map[string]*sampleRing),
+	}
+}
+
+// Observe records the value of the counter key at time at
+func (e *RateEngine) Observe(key string, at time.Time, value int64) {
+	ring, ok := e.series[key]
+	if !ok {
+		ring = newSampleRing(e.capacity)
+		e.series[key] = ring
+	}
+	ring.Add(counterSample{at: at, value: value})
+}
+
+// Rate returns the per-second rate of the counter key over window
+func (e *RateEngine) Rate(key string, window time.Duration) float64 {
+	if ring, ok := e.series[key]; ok {
+		return ring.Rate(window)
+	}
+	return 0
+}
+
+// Retain drops every counter for which keep returns false, so deleted
+// indices and departed nodes do not pile up for the whole session
+func (e *RateEngine) Retain(keep func(key string) bool) {
+	for key := range e.series {
+		if !keep(key) {
+			delete(e.series, key)
+		}
+	}
+}
+
+// Rates holds the per-second rates derived for one window
+type Rates struct {
+	Query     float64            // Search queries across the cluster
+	Index     float64            // Indexing operations across the cluster
+	NetworkTX float64            // Transport bytes sent across the cluster
+	NetworkRX float64            // Transport bytes received across the cluster
+	Indices   map[string]float64 // Indexing operations per index
+}
+
+// Keys of the counters tracked by the collector
+func nodeQueryKey(id string) string    { return "node/" + id + "/query_total" }
+func nodeIndexKey(id string) string    { return "node/" + id + "/index_total" }
+func nodeTxKey(id string) string       { return "node/" + id + "/tx_size_in_bytes" }
+func nodeRxKey(id string) string       { return "node/" + id + "/rx_size_in_bytes" }
+func indexWriteKey(name string) string { return "index/" + name + "/index_total" }
+
+// observeSnapshot feeds the counters of a freshly collected snapshot into the
+// engine. Endpoints that failed are skipped so they do not look like resets.
+func (e *RateEngine) observeSnapshot(snap *Snapshot, at time.Time) {
+	if !snap.Failed(endpointNodesStats) {
+		for id, node := range snap.NodesStats.Nodes {
+			e.Observe(nodeQueryKey(id), at, node.Indices.Search.QueryTotal)
+			e.Observe(nodeIndexKey(id), at, node.Indices.Indexing.IndexTotal)
+			e.Observe(nodeTxKey(id), at, node.Transport.TxSizeInBytes)
+			e.Observe(nodeRxKey(id), at, node.Transport.RxSizeInBytes)
+		}
+	}
+
+	if !snap.Failed(endpointIndexStats) {
+		for name, stats := range snap.IndexWriteStats.Indices {
+			e.Observe(indexWriteKey(name), at, stats.Total.Indexing.IndexTotal)
+		}
+	}
+
+	if !snap.Failed(endpointNodesStats) && !snap.Failed(endpointIndexStats) {
+		live := make(map[string]bool)
+		for id := range snap.NodesStats.Nodes {
+			live[nodeQueryKey(id)] = true
+			live[nodeIndexKey(id)] = true
+			live[nodeTxKey(id)] = true
+			live[nodeRxKey(id)] = true
+		}
+		for name := range snap.IndexWriteStats.Indices {
+			live[indexWriteKey(name)] = true
+		}
+		e.Retain(func(key string) bool { return live[key] })
+	}
+}
+
+// ratesFor derives the cluster and per-index rates of a snapshot over window.
+// Cluster totals are summed from per-node rates so a single node restarting
+// does not wipe out the rate of the whole cluster.
+func (e *RateEngine) ratesFor(snap *Snapshot, window time.Duration) Rates {
+	rates := Rates{Indices: make(map[string]float64)}
+
+	for id := range snap.NodesStats.Nodes {
+		rates.Query += e.Rate(nodeQueryKey(id), window)
+		rates.Index += e.Rate(nodeIndexKey(id), window)
+		rates.NetworkTX += e.Rate(nodeTxKey(id), window)
+		rates.NetworkRX += e.Rate(nodeRxKey(id), window)
+	}
+
+	for name := range snap.IndexWriteStats.Indices {
+		rates.Indices[name] = e.Rate(indexWriteKey(name), window)
+	}
+
+	return rates
+}
+
+// rateWindowsWith returns the default windows plus extra, sorted and without duplicates
+func rateWindowsWith(extra ...time.Duration) []time.Duration {
+	seen := make(map[time.Duration]bool)
+	var windows []time.Duration
+	for _, w := range append(extra, defaultRateWindows...) {
+		if !seen[w] {
+			seen[w] = true
+			windows = append(windows, w)
+		}
+	}
+	sort.Slice(windows, func(i, j int) bool { return windows[i] < windows[j] })
+	return windows
+}
+
+// formatDuration renders a duration the way it is passed on the command line, e.g. 5s or 1m
+func formatDuration(d time.Duration) string {
+	switch {
+	case d%time.Hour == 0:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	case d%time.Minute == 0:
+		return fmt.Sprintf("%dm", d/time.Minute)
+	default:
+		return d.String()
+	}
+}
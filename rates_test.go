@@ -0,0 +1,128 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSampleRingRate(t *testing.T) {
+	type sample struct {
+		second int // Seconds since the first sample
+		value  int64
+	}
+	tests := []struct {
+		name     string
+		capacity int
+		samples  []sample
+		window   time.Duration
+		want     float64
+	}{
+		{
+			name:     "no history",
+			capacity: 10,
+			samples:  []sample{{0, 100}},
+			window:   5 * time.Second,
+			want:     0,
+		},
+		{
+			name:     "full window",
+			capacity: 10,
+			samples:  []sample{{0, 0}, {5, 50}, {10, 150}},
+			window:   5 * time.Second,
+			want:     20,
+		},
+		{
+			name:     "shorter history than the window",
+			capacity: 10,
+			samples:  []sample{{0, 0}, {5, 50}, {10, 150}},
+			window:   time.Minute,
+			want:     15,
+		},
+		{
+			name:     "counter reset",
+			capacity: 10,
+			samples:  []sample{{0, 100}, {5, 200}, {10, 50}, {15, 100}},
+			window:   time.Minute,
+			want:     10,
+		},
+		{
+			name:     "reset as the newest sample",
+			capacity: 10,
+			samples:  []sample{{0, 100}, {5, 200}, {10, 50}},
+			window:   time.Minute,
+			want:     0,
+		},
+		{
+			name:     "oldest samples overwritten",
+			capacity: 3,
+			samples:  []sample{{0, 0}, {5, 500}, {10, 600}, {15, 700}},
+			window:   time.Minute,
+			want:     20,
+		},
+		{
+			name:     "samples at the same time",
+			capacity: 10,
+			samples:  []sample{{0, 100}, {0, 200}},
+			window:   time.Minute,
+			want:     0,
+		},
+	}
+
+	start := time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newSampleRing(tt.capacity)
+			for _, s := range tt.samples {
+				r.Add(counterSample{at: start.Add(time.Duration(s.second) * time.Second), value: s.value})
+			}
+			if got := r.Rate(tt.window); got != tt.want {
+				t.Errorf("rate over %s = %v, want %v", tt.window, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRateEngine(t *testing.T) {
+	e := NewRateEngine(time.Minute)
+	start := time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC)
+
+	snap := &Snapshot{}
+	snap.NodesStats.Nodes = map[string]NodeStats{"a": {}, "b": {}}
+	observe := func(second int, a, b int64) {
+		at := start.Add(time.Duration(second) * time.Second)
+		e.Observe(nodeIndexKey("a"), at, a)
+		e.Observe(nodeIndexKey("b"), at, b)
+	}
+
+	// Node b restarts between the second and third poll: the cluster rate
+	// keeps node a's and starts node b's over
+	observe(0, 1000, 5000)
+	observe(5, 1100, 5500)
+	observe(10, 1200, 100)
+	observe(15, 1300, 200)
+
+	tests := []struct {
+		window time.Duration
+		want   float64
+	}{
+		{5 * time.Second, 20 + 20},
+		{time.Minute, 20 + 20},
+	}
+	for _, tt := range tests {
+		if got := e.ratesFor(snap, tt.window).Index; got != tt.want {
+			t.Errorf("cluster indexing rate over %s = %v, want %v", tt.window, got, tt.want)
+		}
+	}
+
+	if got := e.Rate("node/unknown/index_total", time.Minute); got != 0 {
+		t.Errorf("rate of a counter never observed = %v, want 0", got)
+	}
+
+	e.Retain(func(key string) bool { return key == nodeIndexKey("a") })
+	if got := e.Rate(nodeIndexKey("b"), time.Minute); got != 0 {
+		t.Errorf("rate of a dropped counter = %v, want 0", got)
+	}
+	if got := e.Rate(nodeIndexKey("a"), time.Minute); got != 20 {
+		t.Errorf("rate of a retained counter = %v, want 20", got)
+	}
+}
@@ -0,0 +1,246 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// A recording is a directory with one subdirectory per poll, named after the
+// time the poll started, holding the raw body of every response of that poll:
+//
+//	20261016T091326.069Z/cluster-stats.json
+//	20261016T091326.069Z/nodes-stats.json
+//	20261016T091326.069Z/cat-indices.503.json
+//
+// Responses other than 200 OK carry their status code in the file name.
+// Requests that got no response at all are not recorded.
+const recordTimeFormat = "20060102T150405.000Z"
+
+// recordNames are the file names responses of the polled endpoints are
+// recorded under
+var recordNames = map[string]string{
+	endpointRoot:          "root",
+	endpointClusterStats:  "cluster-stats",
+	endpointNodesInfo:     "nodes",
+	endpointIndices:       "cat-indices",
+	endpointClusterHealth: "cluster-health",
+	endpointNodesStats:    "nodes-stats",
+	endpointIndexStats:    "stats",
+	endpointDataStreams:   "data-stream",
+	endpointNodesHTTP:     "nodes-http",
+}
+
+// recordName returns the file name, without extension, of the request URI
+func recordName(uri string) string {
+	if name, ok := recordNames[uri]; ok {
+		return name
+	}
+	name := strings.Trim(uri, "/")
+	return strings.Map(func(r rune) rune {
+		if r == '/' || r == '?' || r == '&' || r == '=' {
+			return '-'
+		}
+		return r
+	}, name)
+}
+
+// relativeURI returns the request URI of u without the path of the base
+// URL among bases it was sent to, e.g. /_cluster/stats for a request to
+// https://host/es/_cluster/stats with -url https://host/es. Recordings are
+// named after it, so they replay whatever path prefix they were made behind.
+func relativeURI(u *url.URL, bases []*url.URL) string {
+	uri := u.RequestURI()
+	for _, base := range bases {
+		prefix := strings.TrimRight(base.EscapedPath(), "/")
+		if prefix != "" && base.Host == u.Host && strings.HasPrefix(uri, prefix+"/") {
+			return strings.TrimPrefix(uri, prefix)
+		}
+	}
+	return uri
+}
+
+// parseBases parses the base URLs of a client for relativeURI
+func parseBases(urls []string) []*url.URL {
+	var bases []*url.URL
+	for _, raw := range urls {
+		if base, err := url.Parse(raw); err == nil {
+			bases = append(bases, base)
+		}
+	}
+	return bases
+}
+
+type pollTimeKey struct{}
+
+// withPollTime tags the requests of a poll with the time the poll started
+func withPollTime(ctx context.Context, at time.Time) context.Context {
+	return context.WithValue(ctx, pollTimeKey{}, at)
+}
+
+func pollTime(ctx context.Context) (time.Time, bool) {
+	at, ok := ctx.Value(pollTimeKey{}).(time.Time)
+	return at, ok
+}
+
+// Recorder saves every response of a client into a recording, through the
+// client's OnResponse hook
+type Recorder struct {
+	dir   string
+	bases []*url.URL // Seed URLs of the client
+}
+
+// NewRecorder creates the recording directory, failing right away rather
+// than losing the recording when it cannot be written to. seeds are the
+// base URLs of the recorded client.
+func NewRecorder(dir string, seeds []string) (*Recorder, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("recording: %w", err)
+	}
+	probe, err := os.CreateTemp(dir, ".probe-*")
+	if err != nil {
+		return nil, fmt.Errorf("recording: %w", err)
+	}
+	probe.Close()
+	os.Remove(probe.Name())
+	return &Recorder{dir: dir, bases: parseBases(seeds)}, nil
+}
+
+// OnResponse records body. Write errors are dropped, as the hook has no way
+// to report them and the dashboard should keep working regardless.
+func (r *Recorder) OnResponse(req *http.Request, resp *http.Response, body []byte) {
+	at, ok := pollTime(req.Context())
+	if !ok {
+		at = time.Now()
+	}
+	dir := filepath.Join(r.dir, at.UTC().Format(recordTimeFormat))
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return
+	}
+
+	name := recordName(relativeURI(req.URL, r.bases))
+	if resp.StatusCode != http.StatusOK {
+		name += "." + strconv.Itoa(resp.StatusCode)
+	}
+	os.WriteFile(filepath.Join(dir, name+".json"), body, 0o644)
+}
+
+// replayURL is the base URL of the client of a replay, which never leaves
+// the process
+const replayURL = "http://replay"
+
+var replayBases = parseBases([]string{replayURL})
+
+// errEndOfRecording is returned by polls after the last recorded one
+var errEndOfRecording = errors.New("end of recording")
+
+// Replay serves a recording in place of a cluster. It is the transport of
+// the client, and every poll of the collector steps it to the next recorded
+// poll, so the dashboard shows the recording at the pace of -interval. The
+// collector stops polling while the dashboard is paused, so refreshing then
+// steps through it one poll at a time.
+type Replay struct {
+	dir   string
+	polls []time.Time
+
+	mu  sync.Mutex
+	pos int // Index of the poll being served, -1 before the first
+}
+
+// OpenReplay reads the polls of the recording in dir
+func OpenReplay(dir string) (*Replay, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("replay: %w", err)
+	}
+
+	r := &Replay{dir: dir, pos: -1}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		if at, err := time.Parse(recordTimeFormat, entry.Name()); err == nil {
+			r.polls = append(r.polls, at.Local())
+		}
+	}
+	if len(r.polls) == 0 {
+		return nil, fmt.Errorf("replay: no recorded polls in %s", dir)
+	}
+	sort.Slice(r.polls, func(i, j int) bool { return r.polls[i].Before(r.polls[j]) })
+	return r, nil
+}
+
+// Next steps to the next recorded poll and returns the time it was made at
+func (r *Replay) Next() (time.Time, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.pos+1 >= len(r.polls) {
+		return time.Time{}, errEndOfRecording
+	}
+	r.pos++
+	return r.polls[r.pos], nil
+}
+
+// Position describes the poll being served, e.g. "recording 3/120"
+func (r *Replay) Position() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return fmt.Sprintf("recording %d/%d", r.pos+1, len(r.polls))
+}
+
+// RoundTrip answers req with the response recorded for it in the poll its
+// context is tagged with by withPollTime, such as the poll on screen for a
+// detail screen, else in the current poll
+func (r *Replay) RoundTrip(req *http.Request) (*http.Response, error) {
+	at, ok := pollTime(req.Context())
+	if !ok {
+		r.mu.Lock()
+		pos := r.pos
+		r.mu.Unlock()
+		if pos < 0 {
+			return nil, errors.New("replay: no poll started")
+		}
+		at = r.polls[pos]
+	}
+
+	dir := filepath.Join(r.dir, at.UTC().Format(recordTimeFormat))
+	name := recordName(relativeURI(req.URL, replayBases))
+	status := http.StatusOK
+	body, err := os.ReadFile(filepath.Join(dir, name+".json"))
+	if errors.Is(err, os.ErrNotExist) {
+		// Failed requests carry their status code in the file name
+		matches, _ := filepath.Glob(filepath.Join(dir, name+".*.json"))
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("replay: %s was not recorded in this poll", req.URL.RequestURI())
+		}
+		status, _ = strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(filepath.Base(matches[0]), name+"."), ".json"))
+		body, err = os.ReadFile(matches[0])
+	}
+	if err != nil {
+		return nil, fmt.Errorf("replay: %w", err)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": {"application/json"}},
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRecordReplay(t *testing.T) {
+	tests := []struct {
+		name   string
+		prefix string // Path the cluster is served under, as behind a proxy
+	}{
+		{name: "root"},
+		{name: "path prefix", prefix: "/es"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newFakeCluster(t, endpointClusterHealth)
+			if tt.prefix != "" {
+				srv = httptest.NewServer(http.StripPrefix(tt.prefix, srv.Config.Handler))
+				t.Cleanup(srv.Close)
+			}
+			seeds := []string{srv.URL + tt.prefix}
+
+			dir := t.TempDir()
+			recorder, err := NewRecorder(dir, seeds)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			client, err := NewClient(ClientConfig{URLs: seeds, OnResponse: recorder.OnResponse})
+			if err != nil {
+				t.Fatal(err)
+			}
+			live := NewCollector(client, time.Second, defaultRateWindows)
+			recorded, err := live.collect(pollTimeout)
+			if err != nil {
+				t.Fatalf("collect: %v", err)
+			}
+			if len(recorded.Errors) != 1 {
+				t.Fatalf("recorded errors %v, want only %s", recorded.Errors, endpointClusterHealth)
+			}
+
+			polls, _ := filepath.Glob(filepath.Join(dir, "*"))
+			if len(polls) != 1 {
+				t.Fatalf("recorded %d polls, want 1", len(polls))
+			}
+			if _, err := os.Stat(filepath.Join(polls[0], "cluster-health.503.json")); err != nil {
+				t.Errorf("failed response not recorded with its status: %v", err)
+			}
+
+			replay, err := Settings{Replay: dir, Interval: time.Second, RateWindow: time.Minute}.NewCollector(defaultRateWindows, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			replayed, err := replay.collect(pollTimeout)
+			if err != nil {
+				t.Fatalf("replay: %v", err)
+			}
+
+			if !reflect.DeepEqual(replayed.Errors, recorded.Errors) {
+				t.Errorf("replayed errors %v, want the recorded %v", replayed.Errors, recorded.Errors)
+			}
+			if !reflect.DeepEqual(replayed.NodesStats, recorded.NodesStats) || !reflect.DeepEqual(replayed.IndicesStats, recorded.IndicesStats) {
+				t.Error("replayed snapshot differs from the recorded one")
+			}
+			if replayed.Distribution != recorded.Distribution {
+				t.Errorf("replayed distribution %v, want %v", replayed.Distribution, recorded.Distribution)
+			}
+			if want := "recording 1/1"; replayed.ServedBy != want {
+				t.Errorf("replay served by %q, want %q", replayed.ServedBy, want)
+			}
+			// The recorded time is when the poll started, just before it was fetched
+			if d := recorded.FetchedAt.Sub(replayed.FetchedAt); d < 0 || d > time.Second {
+				t.Errorf("replay fetched at %v, want the recorded poll time (fetched at %v)", replayed.FetchedAt, recorded.FetchedAt)
+			}
+
+			if _, err := replay.collect(pollTimeout); err != errEndOfRecording {
+				t.Errorf("poll after the recording = %v, want %v", err, errEndOfRecording)
+			}
+		})
+	}
+}
+
+// TestReplayPause checks that a paused replay stays on its poll and only
+// steps to the next one on a refresh
+func TestReplayPause(t *testing.T) {
+	srv := newFakeCluster(t)
+	dir := t.TempDir()
+	recorder, err := NewRecorder(dir, []string{srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := NewClient(ClientConfig{URLs: []string{srv.URL}, OnResponse: recorder.OnResponse})
+	if err != nil {
+		t.Fatal(err)
+	}
+	live := NewCollector(client, time.Second, defaultRateWindows)
+	for i := 0; i < 3; i++ {
+		if _, err := live.collect(pollTimeout); err != nil {
+			t.Fatalf("collect: %v", err)
+		}
+		// Polls are told apart by the millisecond they started at
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	replay, err := Settings{Replay: dir, Interval: time.Second, RateWindow: time.Minute}.NewCollector(defaultRateWindows, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Far below -interval's minimum, so an unpaused replay would be through
+	// the recording many times over while the test waits
+	replay.interval = 10 * time.Millisecond
+	replay.SetPaused(true)
+
+	polled := make(chan struct{}, 10)
+	go replay.Run(func() { polled <- struct{}{} })
+	next := func() {
+		t.Helper()
+		select {
+		case <-polled:
+		case <-time.After(5 * time.Second):
+			t.Fatal("no poll")
+		}
+	}
+
+	next()
+	time.Sleep(200 * time.Millisecond)
+	if got, want := replay.replay.Position(), "recording 1/3"; got != want || len(polled) > 0 {
+		t.Fatalf("paused replay at %q after %d more polls, want %q", got, len(polled), want)
+	}
+
+	replay.Refresh()
+	next()
+	time.Sleep(200 * time.Millisecond)
+	if got, want := replay.replay.Position(), "recording 2/3"; got != want {
+		t.Errorf("paused replay at %q after a refresh, want %q", got, want)
+	}
+}
@@ -0,0 +1,655 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rivo/tview"
+)
+
+// renderDashboard redraws every panel from the given snapshot. stale marks
+// data that is older than the last poll and pollErr is the reason, if any.
+func (a *App) renderDashboard(snap *Snapshot, stale bool, pollErr error) {
+	if snap == nil {
+		if pollErr != nil {
+			a.header.SetText(fmt.Sprintf("[red]Error: %s", describeError(pollErr)))
+			a.theme.recolorPanels(a.header)
+		}
+		return
+	}
+
+	a.renderHeader(snap, stale, pollErr)
+	a.renderNodesPanel(snap)
+	a.renderIndicesPanel(snap)
+	a.renderMetricsPanel(snap)
+
+	if a.showRoles {
+		updateRolesPanel(a.rolesPanel, snap.NodesInfo)
+	}
+
+	a.theme.recolorPanels(a.header, a.nodesTitle, a.rolesPanel, a.indicesTitle, a.indicesFooter, a.metricsPanel)
+	a.theme.recolorTable(a.nodesTable, a.indicesTable)
+}
+
+// errorBadges returns one line per endpoint in paths that failed according to
+// errs, so a panel can say which of its data sources is missing
+func errorBadges(errs map[string]error, paths ...string) string {
+	var b strings.Builder
+	for _, path := range paths {
+		if err := errs[path]; err != nil {
+			fmt.Fprintf(&b, "[#ff5555]✗ %s:[white] %s\n", path, describeError(err))
+		}
+	}
+	return b.String()
+}
+
+func (a *App) renderHeader(snap *Snapshot, stale bool, pollErr error) {
+	clusterStats := snap.ClusterStats
+
+	a.header.Clear()
+
+	staleStr := ""
+	if stale {
+		staleStr = fmt.Sprintf(" [#ffff00]Stale since %s[white]", snap.FetchedAt.Format("15:04:05"))
+	}
+
+	if snap.Failed(endpointClusterStats) {
+		fmt.Fprintf(a.header, "[#00ffff]Cluster :[white] [#ff5555]✗ %s[white] [#00ffff]Latest: [white]%s%s\n",
+			endpointClusterStats,
+			describeLatest(snap.LatestVersion, snap.VersionChecked),
+			staleStr)
+		fmt.Fprintf(a.header, "[#00ffff]Nodes   :[white] [#444444]unknown[white]%s\n", a.refreshStatus(snap))
+	} else {
+		statusColor := map[string]string{
+			"green":  "green",
+			"yellow": "yellow",
+			"red":    "red",
+		}[clusterStats.Status]
+
+		fmt.Fprintf(a.header, "[#00ffff]Cluster :[white] %s [#666666]([%s]%s[-][#666666]) [#00ffff]Latest: [white]%s%s\n",
+			clusterStats.ClusterName,
+			statusColor,
+			strings.ToUpper(clusterStats.Status),
+			describeLatest(snap.LatestVersion, snap.VersionChecked),
+			staleStr)
+		fmt.Fprintf(a.header, "[#00ffff]Nodes   :[white] %d Total, [green]%d[white] Successful, [#ff5555]%d[white] Failed%s\n",
+			clusterStats.Nodes.Total,
+			clusterStats.Nodes.Successful,
+			clusterStats.Nodes.Failed,
+			a.refreshStatus(snap))
+	}
+
+	switch {
+	case stale && pollErr != nil:
+		fmt.Fprintf(a.header, "[red]Error: %s[white]\n", describeError(pollErr))
+	case snap.Failed(endpointClusterStats):
+		fmt.Fprintf(a.header, "[red]Error: %s[white]\n", describeError(snap.Errors[endpointClusterStats]))
+	default:
+		clusterKey := ""
+		if len(a.clusters) > 1 {
+			clusterKey = " Tab cluster,"
+		}
+		fmt.Fprintf(a.header, "[#666666]Press 2-5 to toggle panels, 'n'/'i' nodes/indices, Enter details, '<'/'>'/'o' sort, '/' filter, 'h' hidden indices, 'w' rate window, 'p' pause, 'r' refresh, '+'/'-' interval,%s 'q' to quit[white]\n", clusterKey)
+	}
+
+	// A mixed-version cluster gets a line of its own below the others
+	height := 3
+	if newest, lagging := mixedVersions(snap.NodesInfo); !snap.Failed(endpointNodesInfo) && len(lagging) > 0 {
+		fmt.Fprintf(a.header, "%s\n", formatMixedVersions(newest, lagging))
+		height++
+	}
+	if height != a.headerHeight {
+		a.headerHeight = height
+		a.updateGridLayout()
+	}
+}
+
+// renderClusterBar lists every cluster colored by its health, with the one
+// on screen highlighted
+func (a *App) renderClusterBar() {
+	var b strings.Builder
+	b.WriteString("[#00ffff]Clusters:[white]")
+	for i, cluster := range a.clusters {
+		snap, stale, err := cluster.collector.State()
+
+		color := "#666666" // Not polled yet
+		switch {
+		case snap == nil && err == nil:
+		case snap == nil || stale:
+			color = "#ff5555"
+		case !snap.Failed(endpointClusterStats):
+			color = getHealthColor(snap.ClusterStats.Status)
+		}
+
+		name := cluster.Name
+		if i == a.current {
+			name = "[::r]" + name + "[::-]"
+		}
+		fmt.Fprintf(&b, "  [%s]●[white] %s", color, name)
+	}
+	b.WriteString("  [#666666](Tab to switch)[white]")
+
+	a.clusterBar.SetText(b.String())
+	a.theme.recolorPanels(a.clusterBar)
+}
+
+// formatMixedVersions warns that nodes run different versions and lists the
+// ones behind, for keeping an eye on rolling upgrades
+func formatMixedVersions(newest string, lagging []laggingNode) string {
+	const maxListed = 5
+
+	var behind []string
+	for i, node := range lagging {
+		if i == maxListed {
+			behind = append(behind, fmt.Sprintf("+%d more", len(lagging)-maxListed))
+			break
+		}
+		behind = append(behind, fmt.Sprintf("%s [#666666](%s)[white]", node.name, node.version))
+	}
+
+	return fmt.Sprintf("[#ffff00]⚠ Mixed versions:[white] %d node(s) behind %s: %s",
+		len(lagging), newest, strings.Join(behind, ", "))
+}
+
+// refreshStatus describes the refresh interval and when and from which node
+// the data on screen was fetched
+func (a *App) refreshStatus(snap *Snapshot) string {
+	status := fmt.Sprintf("  [#00ffff]Refresh:[white] %s [#666666](updated %s via %s)[white]",
+		formatDuration(a.collector.Interval()),
+		snap.FetchedAt.Format("15:04:05"),
+		strings.TrimPrefix(strings.TrimPrefix(snap.ServedBy, "https://"), "http://"))
+	if a.paused {
+		status += " [#ffff00]PAUSED[white]"
+	}
+	return status
+}
+
+// nodeRow is one row of the nodes table
+type nodeRow struct {
+	id     string
+	info   NodeInfo
+	stats  NodeStats
+	latest string // The latest release, outdated versions are colored
+
+	memPercent, heapPercent, diskPercent float64
+	diskUsed, diskTotal                  int64
+}
+
+// nodeColumnName is the column of nodeColumns the nodes are sorted by at startup
+const nodeColumnName = 0
+
+var nodeColumns = []tableColumn[nodeRow]{
+	{
+		title: "Node Name",
+		text:  func(n nodeRow) string { return "[#5555ff]" + n.info.Name },
+		less:  func(a, b nodeRow) bool { return a.info.Name < b.info.Name },
+	},
+	{
+		title: "Roles",
+		text:  func(n nodeRow) string { return formatNodeRoles(n.info.Roles) },
+		// Nodes with the same roles end up next to each other
+		less: func(a, b nodeRow) bool { return strings.Join(a.info.Roles, ",") < strings.Join(b.info.Roles, ",") },
+	},
+	{
+		title: "Transport Address",
+		align: tview.AlignRight,
+		text:  func(n nodeRow) string { return n.info.TransportAddress },
+		less:  func(a, b nodeRow) bool { return a.info.TransportAddress < b.info.TransportAddress },
+	},
+	{
+		title: "Version",
+		text: func(n nodeRow) string {
+			return fmt.Sprintf("[%s]%s", versionColor(n.info.Version, n.latest), n.info.Version)
+		},
+		less: func(a, b nodeRow) bool {
+			if c := compareVersionOrder(a.info.Version, b.info.Version); c != 0 {
+				return c < 0
+			}
+			return a.id < b.id
+		},
+	},
+	{
+		title: "CPU",
+		text: func(n nodeRow) string {
+			cpu := n.stats.OS.CPU.Percent
+			return fmt.Sprintf("[%s]%3d%% [#444444](%d)", getPercentageColor(float64(cpu)), cpu, n.info.OS.AvailableProcessors)
+		},
+		less: func(a, b nodeRow) bool { return a.stats.OS.CPU.Percent < b.stats.OS.CPU.Percent },
+	},
+	{
+		title: "Load 1/5/15m",
+		text: func(n nodeRow) string {
+			return formatLoadAverage(n.stats.OS.LoadAverage, n.info.OS.AvailableProcessors)
+		},
+		less: func(a, b nodeRow) bool { return a.stats.OS.LoadAverage["1m"] < b.stats.OS.LoadAverage["1m"] },
+	},
+	{
+		title: "Memory",
+		text: func(n nodeRow) string {
+			return formatUsage(n.stats.OS.Memory.UsedInBytes, n.stats.OS.Memory.TotalInBytes, n.memPercent)
+		},
+		less: func(a, b nodeRow) bool { return a.memPercent < b.memPercent },
+	},
+	{
+		title: "Heap",
+		text: func(n nodeRow) string {
+			return formatUsage(n.stats.JVM.Memory.HeapUsedInBytes, n.stats.JVM.Memory.HeapMaxInBytes, n.heapPercent)
+		},
+		less: func(a, b nodeRow) bool { return a.heapPercent < b.heapPercent },
+	},
+	{
+		title: "Disk",
+		text:  func(n nodeRow) string { return formatUsage(n.diskUsed, n.diskTotal, n.diskPercent) },
+		less:  func(a, b nodeRow) bool { return a.diskPercent < b.diskPercent },
+	},
+	{
+		title: "Uptime",
+		text:  func(n nodeRow) string { return formatUptime(n.stats.JVM.UptimeInMillis) },
+		less:  func(a, b nodeRow) bool { return a.stats.JVM.UptimeInMillis < b.stats.JVM.UptimeInMillis },
+	},
+	{
+		title: "OS",
+		text: func(n nodeRow) string {
+			return fmt.Sprintf("%s [#bd93f9]%s[white] [#444444](%s)", n.info.OS.PrettyName, n.info.OS.Version, n.info.OS.Arch)
+		},
+		less: func(a, b nodeRow) bool {
+			return a.info.OS.PrettyName+" "+a.info.OS.Version < b.info.OS.PrettyName+" "+b.info.OS.Version
+		},
+	},
+}
+
+// formatUsage renders used and total sizes followed by the percentage used
+func formatUsage(used, total int64, percent float64) string {
+	return fmt.Sprintf("%4s / %4s [%s]%3d%%", formatResourceSize(used), formatResourceSize(total), getPercentageColor(percent), int(percent))
+}
+
+// setTitle writes the title of a panel followed by the badges of its failed
+// endpoints, resizing the title view of the panel to fit them
+func setTitle(panel *tview.Flex, title *tview.TextView, text, badges string) {
+	title.SetText(text + "\n\n" + badges)
+	panel.ResizeItem(title, 2+strings.Count(badges, "\n"), 0)
+}
+
+func (a *App) renderNodesPanel(snap *Snapshot) {
+	setTitle(a.nodesPanel, a.nodesTitle,
+		"[::b][#00ffff][[#ff5555]2[#00ffff]] Nodes Information[::-]",
+		errorBadges(snap.Errors, endpointNodesInfo, endpointNodesStats))
+
+	var rows []nodeRow
+	for id, nodeInfo := range snap.NodesInfo.Nodes {
+		nodeStats, exists := snap.NodesStats.Nodes[id]
+		if !exists {
+			continue
+		}
+
+		diskUsed, diskTotal := nodeDiskUsage(nodeStats.FS)
+		rows = append(rows, nodeRow{
+			id:          id,
+			info:        nodeInfo,
+			stats:       nodeStats,
+			latest:      snap.LatestVersion,
+			memPercent:  float64(nodeStats.OS.Memory.UsedInBytes) / float64(nodeStats.OS.Memory.TotalInBytes) * 100,
+			heapPercent: float64(nodeStats.JVM.Memory.HeapUsedInBytes) / float64(nodeStats.JVM.Memory.HeapMaxInBytes) * 100,
+			diskUsed:    diskUsed,
+			diskTotal:   diskTotal,
+			diskPercent: float64(diskUsed) / float64(diskTotal) * 100,
+		})
+	}
+	// Nodes that sort the same stay in name order
+	sort.Slice(rows, func(i, j int) bool { return rows[i].info.Name < rows[j].info.Name })
+
+	fillTable(a.nodesTable, nodeColumns, rows, func(n nodeRow) string { return n.id }, &a.nodesSort, a.render)
+}
+
+// indexColumnRate is the column of indexColumns the indices are sorted by at
+// startup, busiest first
+const indexColumnRate = 7
+
+var indexColumns = []tableColumn[indexInfo]{
+	{
+		// Whether the index is being written to and whether it backs a data stream
+		text: func(idx indexInfo) string {
+			writeIcon := "[#444444]⚪"
+			if idx.indexingRate > 0 {
+				writeIcon = "[#5555ff]⚫"
+			}
+			streamIndicator := " "
+			if idx.dataStream {
+				streamIndicator = "[#bd93f9]⚫"
+			}
+			return writeIcon + streamIndicator
+		},
+	},
+	{
+		title: "Index Name",
+		text:  func(idx indexInfo) string { return fmt.Sprintf("[%s]%s", getHealthColor(idx.health), idx.index) },
+		less:  func(a, b indexInfo) bool { return a.index < b.index },
+	},
+	{
+		title: "Documents",
+		align: tview.AlignRight,
+		text:  func(idx indexInfo) string { return formatNumber(idx.docs) },
+		less:  func(a, b indexInfo) bool { return a.docs < b.docs },
+	},
+	{
+		title: "Size",
+		align: tview.AlignRight,
+		text:  func(idx indexInfo) string { return convertSizeFormat(idx.storeSize) },
+		less:  func(a, b indexInfo) bool { return parseStoreSize(a.storeSize) < parseStoreSize(b.storeSize) },
+	},
+	{
+		title: "Shards",
+		align: tview.AlignRight,
+		text:  func(idx indexInfo) string { return idx.priShards },
+		less:  func(a, b indexInfo) bool { return atoi(a.priShards) < atoi(b.priShards) },
+	},
+	{
+		title: "Replicas",
+		align: tview.AlignRight,
+		text:  func(idx indexInfo) string { return idx.replicas },
+		less:  func(a, b indexInfo) bool { return atoi(a.replicas) < atoi(b.replicas) },
+	},
+	{
+		title: "Ingested",
+		text: func(idx indexInfo) string {
+			if idx.ingested <= 0 {
+				return ""
+			}
+			return "[green]+" + formatNumber(idx.ingested)
+		},
+		less: func(a, b indexInfo) bool { return a.ingested < b.ingested },
+	},
+	{
+		title: "Rate",
+		text:  func(idx indexInfo) string { return formatIndexingRate(idx.indexingRate) },
+		less:  func(a, b indexInfo) bool { return a.indexingRate < b.indexingRate },
+	},
+}
+
+// formatIndexingRate renders the indexing rate of one index
+func formatIndexingRate(rate float64) string {
+	switch {
+	case rate >= 1000:
+		return fmt.Sprintf("[#50fa7b]%.1fk/s", rate/1000)
+	case rate > 0:
+		return fmt.Sprintf("[#50fa7b]%.1f/s", rate)
+	default:
+		return "[#444444]0/s"
+	}
+}
+
+// atoi parses the numbers _cat APIs return as strings, 0 if there is none
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+func (a *App) renderIndicesPanel(snap *Snapshot) {
+	indexWriteStats := snap.IndexWriteStats
+	clusterHealth := snap.ClusterHealth
+	rates := snap.Rates[a.rateWindow]
+
+	var indices []indexInfo
+	var totalDocs int
+	var totalSize int64
+	listed := 0
+
+	// Collect index information
+	for _, index := range snap.IndicesStats {
+		// Skip hidden indices unless showHiddenIndices is true
+		if (!a.showHiddenIndices && strings.HasPrefix(index.Index, ".")) || index.DocsCount == "0" {
+			continue
+		}
+		docs := 0
+		fmt.Sscanf(index.DocsCount, "%d", &docs)
+
+		// Track document changes
+		activity, exists := a.indexActivities[index.Index]
+		if !exists {
+			activity = &IndexActivity{InitialDocsCount: docs}
+			a.indexActivities[index.Index] = activity
+		}
+
+		// Get write operations count and rate
+		writeOps := int64(0)
+		indexingRate := float64(0)
+		if stats, exists := indexWriteStats.Indices[index.Index]; exists {
+			writeOps = stats.Total.Indexing.IndexTotal
+			indexingRate = rates.Indices[index.Index]
+		}
+
+		idx := indexInfo{
+			index:        index.Index,
+			health:       index.Health,
+			docs:         docs,
+			storeSize:    index.StoreSize,
+			priShards:    index.PriShards,
+			replicas:     index.Replicas,
+			writeOps:     writeOps,
+			indexingRate: indexingRate,
+			ingested:     docs - activity.InitialDocsCount,
+			dataStream:   isDataStream(index.Index, snap.DataStreams),
+		}
+		listed++
+
+		// Indices the filter leaves out do not count in the totals
+		if !a.indexFilter.match(idx) {
+			continue
+		}
+		indices = append(indices, idx)
+		totalDocs += docs
+		totalSize += parseStoreSize(idx.storeSize)
+	}
+
+	// Unfiltered, the total size is what the nodes use on disk. Without nodes
+	// stats it falls back to the size of the indices listed.
+	totalSizeStr := bytesToHuman(totalSize)
+	if a.indexFilter == nil {
+		switch {
+		case !snap.Failed(endpointNodesStats):
+			totalSize = 0
+			for _, node := range snap.NodesStats.Nodes {
+				totalSize += node.FS.Total.TotalInBytes - node.FS.Total.AvailableInBytes
+			}
+			totalSizeStr = bytesToHuman(totalSize)
+		case snap.Failed(endpointIndices):
+			totalSizeStr = "[#444444]unknown[white]"
+		}
+	}
+
+	title := "[::b][#00ffff][[#ff5555]4[#00ffff]] Indices Information[::-]"
+	if a.indexFilter != nil {
+		title += fmt.Sprintf(" [#666666]filter[white] %s [#666666](%d of %d)[white]", tview.Escape(a.indexFilter.expr), len(indices), listed)
+	}
+	if a.filterErr != nil {
+		title += fmt.Sprintf(" [#ff5555]✗ %s[white]", tview.Escape(a.filterErr.Error()))
+	}
+	setTitle(a.indicesPanel, a.indicesTitle, title,
+		errorBadges(snap.Errors, endpointIndices, endpointIndexStats, endpointDataStreams, endpointClusterHealth))
+
+	// Indices that sort the same stay in name order
+	sort.Slice(indices, func(i, j int) bool { return indices[i].index < indices[j].index })
+
+	fillTable(a.indicesTable, indexColumns, indices, func(idx indexInfo) string { return idx.index }, &a.indicesSort, a.render)
+
+	// Calculate total indexing rate for the cluster
+	totalIndexingRate := float64(0)
+	for _, idx := range indices {
+		totalIndexingRate += idx.indexingRate
+	}
+
+	// Format cluster indexing rate
+	clusterRateStr := ""
+	if totalIndexingRate > 0 {
+		if totalIndexingRate >= 1000000 {
+			clusterRateStr = fmt.Sprintf("[#50fa7b]%.1fM/s", totalIndexingRate/1000000)
+		} else if totalIndexingRate >= 1000 {
+			clusterRateStr = fmt.Sprintf("[#50fa7b]%.1fK/s", totalIndexingRate/1000)
+		} else {
+			clusterRateStr = fmt.Sprintf("[#50fa7b]%.1f/s", totalIndexingRate)
+		}
+	} else {
+		clusterRateStr = "[#444444]0/s"
+	}
+
+	// Display the totals with indexing rate
+	a.indicesFooter.Clear()
+	fmt.Fprintf(a.indicesFooter, "\n[#00ffff]Total Documents:[white] %s, [#00ffff]Total Size:[white] %s, [#00ffff]Indexing Rate:[white] %s [#444444](%s)[white]\n",
+		formatNumber(totalDocs),
+		totalSizeStr,
+		clusterRateStr,
+		formatDuration(a.rateWindow))
+
+	// Move shard stats to bottom of indices panel
+	footerHeight := 2
+	if !snap.Failed(endpointClusterHealth) {
+		fmt.Fprintf(a.indicesFooter, "\n[#00ffff]Shard Status:[white] Active: %d (%.1f%%), Primary: %d, Relocating: %d, Initializing: %d, Unassigned: %d\n",
+			clusterHealth.ActiveShards,
+			clusterHealth.ActiveShardsPercentAsNumber,
+			clusterHealth.ActivePrimaryShards,
+			clusterHealth.RelocatingShards,
+			clusterHealth.InitializingShards,
+			clusterHealth.UnassignedShards)
+		footerHeight = 4
+	}
+	a.indicesPanel.ResizeItem(a.indicesFooter, footerHeight, 0)
+}
+
+func (a *App) renderMetricsPanel(snap *Snapshot) {
+	clusterStats := snap.ClusterStats
+	nodesStats := snap.NodesStats
+
+	a.metricsPanel.Clear()
+	fmt.Fprintf(a.metricsPanel, "[::b][#00ffff][[#ff5555]5[#00ffff]] Cluster Metrics[::-]\n\n")
+	fmt.Fprint(a.metricsPanel, errorBadges(snap.Errors, endpointClusterStats, endpointNodesStats))
+
+	// Define metrics keys with proper grouping
+	metricKeys := []string{
+		// System metrics
+		"CPU",
+		"Memory",
+		"Heap",
+		"Disk",
+
+		// Network metrics
+		"Network TX",
+		"Network RX",
+		"HTTP Connections",
+
+		// Performance metrics
+		"Query Rate",
+		"Index Rate",
+
+		// Miscellaneous
+		"Snapshots",
+	}
+
+	// Find the longest key for proper alignment
+	maxKeyLength := 0
+	for _, key := range metricKeys {
+		if len(key) > maxKeyLength {
+			maxKeyLength = len(key)
+		}
+	}
+
+	// Add padding for better visual separation
+	maxKeyLength += 2
+
+	// Rows are gathered first so the sparklines can line up after the widest value
+	type metricRow struct {
+		name  string
+		value string
+	}
+	var rows []metricRow
+	addMetric := func(name string, value string) {
+		rows = append(rows, metricRow{name: name, value: value})
+	}
+
+	// CPU metrics
+	if !snap.Failed(endpointClusterStats) {
+		totalProcessors := 0
+		for _, node := range snap.NodesInfo.Nodes {
+			totalProcessors += node.OS.AvailableProcessors
+		}
+		cpuPercent := float64(clusterStats.Process.CPU.Percent)
+		addMetric("CPU", fmt.Sprintf("%7.1f%% [#444444](%d processors)[white]", cpuPercent, totalProcessors))
+	}
+
+	if !snap.Failed(endpointNodesStats) {
+		rates := snap.Rates[a.rateWindow]
+
+		// Disk metrics
+		diskUsed := getTotalSize(nodesStats)
+		diskTotal := getTotalDiskSpace(nodesStats)
+		diskPercent := float64(diskUsed) / float64(diskTotal) * 100
+		addMetric("Disk", fmt.Sprintf("%8s / %8s [%s]%5.1f%%[white]",
+			bytesToHuman(diskUsed),
+			bytesToHuman(diskTotal),
+			getPercentageColor(diskPercent),
+			diskPercent))
+
+		// Calculate heap and memory totals
+		var (
+			totalHeapUsed    int64
+			totalHeapMax     int64
+			totalMemoryUsed  int64
+			totalMemoryTotal int64
+		)
+
+		for _, node := range nodesStats.Nodes {
+			totalHeapUsed += node.JVM.Memory.HeapUsedInBytes
+			totalHeapMax += node.JVM.Memory.HeapMaxInBytes
+			totalMemoryUsed += node.OS.Memory.UsedInBytes
+			totalMemoryTotal += node.OS.Memory.TotalInBytes
+		}
+
+		// Heap metrics
+		heapPercent := float64(totalHeapUsed) / float64(totalHeapMax) * 100
+		addMetric("Heap", fmt.Sprintf("%8s / %8s [%s]%5.1f%%[white]",
+			bytesToHuman(totalHeapUsed),
+			bytesToHuman(totalHeapMax),
+			getPercentageColor(heapPercent),
+			heapPercent))
+
+		// Memory metrics
+		memoryPercent := float64(totalMemoryUsed) / float64(totalMemoryTotal) * 100
+		addMetric("Memory", fmt.Sprintf("%8s / %8s [%s]%5.1f%%[white]",
+			bytesToHuman(totalMemoryUsed),
+			bytesToHuman(totalMemoryTotal),
+			getPercentageColor(memoryPercent),
+			memoryPercent))
+
+		// Network metrics
+		addMetric("Network TX", fmt.Sprintf(" %7s [#444444](%s/s)[white]", bytesToHuman(getTotalNetworkTX(nodesStats)), bytesToHuman(int64(rates.NetworkTX))))
+		addMetric("Network RX", fmt.Sprintf(" %7s [#444444](%s/s)[white]", bytesToHuman(getTotalNetworkRX(nodesStats)), bytesToHuman(int64(rates.NetworkRX))))
+
+		// HTTP Connections and Shard metrics - right aligned to match Network RX 'G'
+		addMetric("HTTP Connections", fmt.Sprintf("%8s", formatNumber(int(getTotalHTTPConnections(nodesStats)))))
+		window := formatDuration(a.rateWindow)
+		addMetric("Query Rate", fmt.Sprintf("%6s/s [#444444](%s)[white]", formatNumber(int(rates.Query)), window))
+		addMetric("Index Rate", fmt.Sprintf("%6s/s [#444444](%s)[white]", formatNumber(int(rates.Index)), window))
+	}
+
+	// Snapshots
+	if !snap.Failed(endpointClusterStats) {
+		addMetric("Snapshots", fmt.Sprintf("%8s", formatNumber(clusterStats.Snapshots.Count)))
+	}
+
+	maxValueWidth := 0
+	for _, row := range rows {
+		if width := tview.TaggedStringWidth(row.value); width > maxValueWidth {
+			maxValueWidth = width
+		}
+	}
+
+	for _, row := range rows {
+		line := fmt.Sprintf("[#00ffff]%-*s[white] %s", maxKeyLength, row.name+":", row.value)
+		if values, ok := snap.History[row.name]; ok {
+			padding := maxValueWidth - tview.TaggedStringWidth(row.value)
+			line += strings.Repeat(" ", padding) + " " + formatSparkline(row.name, values)
+		}
+		fmt.Fprintln(a.metricsPanel, line)
+	}
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata/golden")
+
+// screenText draws p onto a simulated screen of the given size and returns
+// what it shows, one line per row with trailing blanks trimmed. Colors are
+// left out, only the layout is compared.
+func screenText(t *testing.T, p tview.Primitive, width, height int) string {
+	t.Helper()
+
+	screen := tcell.NewSimulationScreen("UTF-8")
+	if err := screen.Init(); err != nil {
+		t.Fatalf("screen: %v", err)
+	}
+	defer screen.Fini()
+	screen.SetSize(width, height)
+
+	p.SetRect(0, 0, width, height)
+	p.Draw(screen)
+	screen.Show()
+
+	cells, w, h := screen.GetContents()
+	var b strings.Builder
+	for y := 0; y < h; y++ {
+		var line strings.Builder
+		for x := 0; x < w; x++ {
+			cell := cells[y*w+x]
+			if len(cell.Runes) == 0 {
+				line.WriteByte(' ')
+				continue
+			}
+			line.WriteString(string(cell.Runes))
+		}
+		b.WriteString(strings.TrimRight(line.String(), " "))
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// checkGolden compares got with testdata/golden/name.txt, or rewrites the
+// file when the tests run with -update
+func checkGolden(t *testing.T, name, got string) {
+	t.Helper()
+
+	path := filepath.Join("testdata", "golden", name+".txt")
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run go test -update to create it)", err)
+	}
+	if got != string(want) {
+		t.Errorf("%s differs from %s (run go test -update to accept):\n--- got\n%s--- want\n%s", name, path, got, want)
+	}
+}
+
+func newTestApp(collector *Collector) *App {
+	return NewApp([]*Cluster{NewCluster("prod", collector)}, AppOptions{
+		RateWindow: time.Minute,
+		Theme:      themes["default"],
+		Panels:     Panels{Nodes: true, Roles: true, Indices: true, Metrics: true},
+	})
+}
+
+func TestRenderPanels(t *testing.T) {
+	tests := []struct {
+		name    string
+		failing []string
+		setup   func(*App)
+	}{
+		{name: "default"},
+		{name: "hidden", setup: func(a *App) { a.showHiddenIndices = true }},
+		{name: "failed", failing: []string{endpointNodesStats, endpointClusterHealth}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			collector, snap := collectFake(t, tt.failing...)
+			a := newTestApp(collector)
+			if tt.setup != nil {
+				tt.setup(a)
+			}
+			a.renderDashboard(snap, false, nil)
+
+			panels := []struct {
+				name          string
+				view          tview.Primitive
+				width, height int
+			}{
+				{"header", a.header, 230, 4},
+				{"nodes", a.nodesPanel, 230, 7},
+				{"roles", a.rolesPanel, 30, 32},
+				{"indices", a.indicesPanel, 120, 16},
+				{"metrics", a.metricsPanel, 80, 20},
+			}
+			for _, p := range panels {
+				checkGolden(t, tt.name+"-"+p.name, screenText(t, p.view, p.width, p.height))
+			}
+		})
+	}
+}
+
+// TestRenderDashboard checks how the panels are laid out together
+func TestRenderDashboard(t *testing.T) {
+	collector, snap := collectFake(t)
+	a := newTestApp(collector)
+	a.renderDashboard(snap, false, nil)
+	checkGolden(t, "dashboard", screenText(t, a.root, 220, 50))
+}
+
+// TestRenderFilter checks the indices panel listing and totalling only the
+// indices matching a filter, and keeping it while an invalid one is typed
+func TestRenderFilter(t *testing.T) {
+	collector, snap := collectFake(t)
+	a := newTestApp(collector)
+	a.setIndexFilter("2026.10 docs:>10m")
+	a.setIndexFilter("2026.10 docs:>10m /(")
+	a.renderDashboard(snap, false, nil)
+	checkGolden(t, "filtered-indices", screenText(t, a.indicesPanel, 120, 16))
+}
+
+// TestRenderStale checks the header of a cluster that stopped answering
+func TestRenderStale(t *testing.T) {
+	collector, snap := collectFake(t)
+	a := newTestApp(collector)
+	a.renderDashboard(snap, true, errors.New("connection refused"))
+	checkGolden(t, "stale-header", screenText(t, a.header, 160, 3))
+}
+
+// TestRenderPaused checks that redrawing a paused view, as keys and clicks
+// do, keeps the numbers on screen until a refresh lets new ones through
+func TestRenderPaused(t *testing.T) {
+	collector, snap := collectFake(t)
+	a := newTestApp(collector)
+	poll := func(s *Snapshot) {
+		collector.mu.Lock()
+		collector.snapshot = s
+		collector.mu.Unlock()
+		a.update()
+	}
+	press := func(r rune) { a.handleKey(tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone)) }
+	nodes := func() string {
+		return strings.Split(screenText(t, a.header, 160, 3), "\n")[1]
+	}
+
+	poll(snap)
+	press('p')
+	grown := *snap
+	grown.ClusterStats.Nodes.Total = 4
+	poll(&grown)
+	for _, r := range "hw<o" {
+		press(r)
+		if got := nodes(); !strings.Contains(got, "3 Total") {
+			t.Fatalf("after %q while paused the header shows %q, want the 3 nodes on screen", r, got)
+		}
+	}
+
+	press('r')
+	poll(&grown)
+	if got := nodes(); !strings.Contains(got, "4 Total") {
+		t.Errorf("after a refresh while paused the header shows %q, want the 4 nodes polled", got)
+	}
+}
@@ -0,0 +1,281 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Report holds the values the dashboard shows, computed the same way but
+// without any formatting, for scripts (-once) and the Prometheus exporter
+type Report struct {
+	Name       string            `json:"name,omitempty"`
+	FetchedAt  time.Time         `json:"fetched_at"`
+	RateWindow string            `json:"rate_window"`
+	Cluster    ClusterReport     `json:"cluster"`
+	Nodes      []NodeReport      `json:"nodes"`
+	Indices    []IndexReport     `json:"indices"`
+	Shards     *ShardReport      `json:"shards,omitempty"`
+	Metrics    MetricsReport     `json:"metrics"`
+	Errors     map[string]string `json:"errors,omitempty"`
+}
+
+type ClusterReport struct {
+	Name            string        `json:"name"`
+	Status          string        `json:"status"`
+	Distribution    string        `json:"distribution"`
+	Version         string        `json:"version,omitempty"`
+	LatestVersion   string        `json:"latest_version,omitempty"`
+	ServedBy        string        `json:"served_by"`
+	NodesTotal      int           `json:"nodes_total"`
+	NodesSuccessful int           `json:"nodes_successful"`
+	NodesFailed     int           `json:"nodes_failed"`
+	LaggingNodes    []LaggingNode `json:"lagging_nodes,omitempty"`
+}
+
+// LaggingNode is a node running an older version than the newest one
+type LaggingNode struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type NodeReport struct {
+	ID               string             `json:"id"`
+	Name             string             `json:"name"`
+	Roles            []string           `json:"roles"`
+	TransportAddress string             `json:"transport_address"`
+	Version          string             `json:"version"`
+	CPUPercent       int                `json:"cpu_percent"`
+	Processors       int                `json:"processors"`
+	LoadAverage      map[string]float64 `json:"load_average,omitempty"`
+	MemoryUsedBytes  int64              `json:"memory_used_bytes"`
+	MemoryTotalBytes int64              `json:"memory_total_bytes"`
+	MemoryPercent    float64            `json:"memory_percent"`
+	HeapUsedBytes    int64              `json:"heap_used_bytes"`
+	HeapMaxBytes     int64              `json:"heap_max_bytes"`
+	HeapPercent      float64            `json:"heap_percent"`
+	DiskUsedBytes    int64              `json:"disk_used_bytes"`
+	DiskTotalBytes   int64              `json:"disk_total_bytes"`
+	DiskPercent      float64            `json:"disk_percent"`
+	GCYoungCount     int64              `json:"gc_young_count"`
+	GCYoungMillis    int64              `json:"gc_young_time_ms"`
+	GCOldCount       int64              `json:"gc_old_count"`
+	GCOldMillis      int64              `json:"gc_old_time_ms"`
+	UptimeMillis     int64              `json:"uptime_ms"`
+	OS               string             `json:"os"`
+}
+
+type IndexReport struct {
+	Name         string  `json:"name"`
+	Health       string  `json:"health"`
+	Docs         int     `json:"docs"`
+	StoreSize    string  `json:"store_size"`
+	Primaries    string  `json:"primaries"`
+	Replicas     string  `json:"replicas"`
+	IndexTotal   int64   `json:"index_total"`
+	IndexingRate float64 `json:"indexing_rate"`
+	IngestedDocs int     `json:"ingested_docs"`
+	DataStream   bool    `json:"data_stream"`
+	Hidden       bool    `json:"hidden"`
+}
+
+type ShardReport struct {
+	Active        int     `json:"active"`
+	ActivePercent float64 `json:"active_percent"`
+	Primary       int     `json:"primary"`
+	Relocating    int     `json:"relocating"`
+	Initializing  int     `json:"initializing"`
+	Unassigned    int     `json:"unassigned"`
+}
+
+// MetricsReport holds the metrics panel. Values whose endpoint failed are
+// left out rather than reported as zero.
+type MetricsReport struct {
+	CPUPercent       *int     `json:"cpu_percent,omitempty"`
+	Processors       *int     `json:"processors,omitempty"`
+	DiskUsedBytes    *int64   `json:"disk_used_bytes,omitempty"`
+	DiskTotalBytes   *int64   `json:"disk_total_bytes,omitempty"`
+	DiskPercent      *float64 `json:"disk_percent,omitempty"`
+	HeapUsedBytes    *int64   `json:"heap_used_bytes,omitempty"`
+	HeapMaxBytes     *int64   `json:"heap_max_bytes,omitempty"`
+	HeapPercent      *float64 `json:"heap_percent,omitempty"`
+	MemoryUsedBytes  *int64   `json:"memory_used_bytes,omitempty"`
+	MemoryTotalBytes *int64   `json:"memory_total_bytes,omitempty"`
+	MemoryPercent    *float64 `json:"memory_percent,omitempty"`
+	NetworkTXBytes   *int64   `json:"network_tx_bytes,omitempty"`
+	NetworkRXBytes   *int64   `json:"network_rx_bytes,omitempty"`
+	NetworkTXRate    *float64 `json:"network_tx_bytes_per_second,omitempty"`
+	NetworkRXRate    *float64 `json:"network_rx_bytes_per_second,omitempty"`
+	HTTPConnections  *int64   `json:"http_connections,omitempty"`
+	QueryRate        *float64 `json:"query_rate,omitempty"`
+	IndexRate        *float64 `json:"index_rate,omitempty"`
+	IndexingRate     float64  `json:"indexing_rate"` // Sum of the per-index rates
+	Snapshots        *int     `json:"snapshots,omitempty"`
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+// nodeDiskUsage returns the used and total bytes of the node's data path,
+// falling back to the totals over all paths when it has none
+func nodeDiskUsage(fs NodeFS) (used, total int64) {
+	if len(fs.Data) > 0 {
+		return fs.Data[0].TotalInBytes - fs.Data[0].AvailableInBytes, fs.Data[0].TotalInBytes
+	}
+	return fs.Total.TotalInBytes - fs.Total.AvailableInBytes, fs.Total.TotalInBytes
+}
+
+// trackIndexActivity records the first document count seen of every index as
+// the baseline the ingested count is measured against
+func trackIndexActivity(activities map[string]*IndexActivity, indices IndexStats) {
+	for _, index := range indices {
+		docs := 0
+		fmt.Sscanf(index.DocsCount, "%d", &docs)
+		if _, ok := activities[index.Index]; !ok {
+			activities[index.Index] = &IndexActivity{InitialDocsCount: docs}
+		}
+	}
+}
+
+// buildReport computes the report of snap with rates over window. Ingested
+// documents are counted since the baselines in activities, which may be nil.
+func buildReport(snap *Snapshot, window time.Duration, activities map[string]*IndexActivity) Report {
+	rates := snap.Rates[window]
+	report := Report{
+		FetchedAt:  snap.FetchedAt,
+		RateWindow: formatDuration(window),
+		Cluster: ClusterReport{
+			Name:            snap.ClusterStats.ClusterName,
+			Status:          snap.ClusterStats.Status,
+			Distribution:    snap.Distribution.Name,
+			Version:         snap.Distribution.Version,
+			LatestVersion:   snap.LatestVersion,
+			ServedBy:        snap.ServedBy,
+			NodesTotal:      snap.ClusterStats.Nodes.Total,
+			NodesSuccessful: snap.ClusterStats.Nodes.Successful,
+			NodesFailed:     snap.ClusterStats.Nodes.Failed,
+		},
+		Nodes:   []NodeReport{},
+		Indices: []IndexReport{},
+	}
+
+	if len(snap.Errors) > 0 {
+		report.Errors = make(map[string]string, len(snap.Errors))
+		for path, err := range snap.Errors {
+			report.Errors[path] = describeError(err)
+		}
+	}
+
+	_, lagging := mixedVersions(snap.NodesInfo)
+	for _, node := range lagging {
+		report.Cluster.LaggingNodes = append(report.Cluster.LaggingNodes, LaggingNode{Name: node.name, Version: node.version})
+	}
+
+	for id, info := range snap.NodesInfo.Nodes {
+		stats, ok := snap.NodesStats.Nodes[id]
+		if !ok {
+			continue
+		}
+		diskUsed, diskTotal := nodeDiskUsage(stats.FS)
+		report.Nodes = append(report.Nodes, NodeReport{
+			ID:               id,
+			Name:             info.Name,
+			Roles:            info.Roles,
+			TransportAddress: info.TransportAddress,
+			Version:          info.Version,
+			CPUPercent:       stats.OS.CPU.Percent,
+			Processors:       info.OS.AvailableProcessors,
+			LoadAverage:      stats.OS.LoadAverage,
+			MemoryUsedBytes:  stats.OS.Memory.UsedInBytes,
+			MemoryTotalBytes: stats.OS.Memory.TotalInBytes,
+			MemoryPercent:    percent(stats.OS.Memory.UsedInBytes, stats.OS.Memory.TotalInBytes),
+			HeapUsedBytes:    stats.JVM.Memory.HeapUsedInBytes,
+			HeapMaxBytes:     stats.JVM.Memory.HeapMaxInBytes,
+			HeapPercent:      percent(stats.JVM.Memory.HeapUsedInBytes, stats.JVM.Memory.HeapMaxInBytes),
+			DiskUsedBytes:    diskUsed,
+			DiskTotalBytes:   diskTotal,
+			DiskPercent:      percent(diskUsed, diskTotal),
+			GCYoungCount:     stats.JVM.GC.Collectors.Young.CollectionCount,
+			GCYoungMillis:    stats.JVM.GC.Collectors.Young.CollectionTimeInMillis,
+			GCOldCount:       stats.JVM.GC.Collectors.Old.CollectionCount,
+			GCOldMillis:      stats.JVM.GC.Collectors.Old.CollectionTimeInMillis,
+			UptimeMillis:     stats.JVM.UptimeInMillis,
+			OS:               strings.TrimSpace(fmt.Sprintf("%s %s", info.OS.PrettyName, info.OS.Version)),
+		})
+	}
+	sort.Slice(report.Nodes, func(i, j int) bool { return report.Nodes[i].Name < report.Nodes[j].Name })
+
+	for _, index := range snap.IndicesStats {
+		docs := 0
+		fmt.Sscanf(index.DocsCount, "%d", &docs)
+		ir := IndexReport{
+			Name:         index.Index,
+			Health:       index.Health,
+			Docs:         docs,
+			StoreSize:    index.StoreSize,
+			Primaries:    index.PriShards,
+			Replicas:     index.Replicas,
+			IndexingRate: rates.Indices[index.Index],
+			DataStream:   isDataStream(index.Index, snap.DataStreams),
+			Hidden:       strings.HasPrefix(index.Index, "."),
+		}
+		if stats, ok := snap.IndexWriteStats.Indices[index.Index]; ok {
+			ir.IndexTotal = stats.Total.Indexing.IndexTotal
+		}
+		if activity := activities[index.Index]; activity != nil && activity.InitialDocsCount < docs {
+			ir.IngestedDocs = docs - activity.InitialDocsCount
+		}
+		report.Metrics.IndexingRate += ir.IndexingRate
+		report.Indices = append(report.Indices, ir)
+	}
+	sort.Slice(report.Indices, func(i, j int) bool { return report.Indices[i].Name < report.Indices[j].Name })
+
+	if !snap.Failed(endpointClusterHealth) {
+		health := snap.ClusterHealth
+		report.Shards = &ShardReport{
+			Active:        health.ActiveShards,
+			ActivePercent: health.ActiveShardsPercentAsNumber,
+			Primary:       health.ActivePrimaryShards,
+			Relocating:    health.RelocatingShards,
+			Initializing:  health.InitializingShards,
+			Unassigned:    health.UnassignedShards,
+		}
+	}
+
+	m := &report.Metrics
+	if !snap.Failed(endpointClusterStats) {
+		processors := 0
+		for _, node := range snap.NodesInfo.Nodes {
+			processors += node.OS.AvailableProcessors
+		}
+		m.CPUPercent = ptr(snap.ClusterStats.Process.CPU.Percent)
+		m.Processors = ptr(processors)
+		m.Snapshots = ptr(snap.ClusterStats.Snapshots.Count)
+	}
+
+	if !snap.Failed(endpointNodesStats) {
+		var heapUsed, heapMax, memUsed, memTotal int64
+		for _, node := range snap.NodesStats.Nodes {
+			heapUsed += node.JVM.Memory.HeapUsedInBytes
+			heapMax += node.JVM.Memory.HeapMaxInBytes
+			memUsed += node.OS.Memory.UsedInBytes
+			memTotal += node.OS.Memory.TotalInBytes
+		}
+		diskUsed, diskTotal := getTotalSize(snap.NodesStats), getTotalDiskSpace(snap.NodesStats)
+
+		m.DiskUsedBytes, m.DiskTotalBytes, m.DiskPercent = ptr(diskUsed), ptr(diskTotal), ptr(percent(diskUsed, diskTotal))
+		m.HeapUsedBytes, m.HeapMaxBytes, m.HeapPercent = ptr(heapUsed), ptr(heapMax), ptr(percent(heapUsed, heapMax))
+		m.MemoryUsedBytes, m.MemoryTotalBytes, m.MemoryPercent = ptr(memUsed), ptr(memTotal), ptr(percent(memUsed, memTotal))
+		m.NetworkTXBytes = ptr(getTotalNetworkTX(snap.NodesStats))
+		m.NetworkRXBytes = ptr(getTotalNetworkRX(snap.NodesStats))
+		m.NetworkTXRate = ptr(rates.NetworkTX)
+		m.NetworkRXRate = ptr(rates.NetworkRX)
+		m.HTTPConnections = ptr(getTotalHTTPConnections(snap.NodesStats))
+		m.QueryRate = ptr(rates.Query)
+		m.IndexRate = ptr(rates.Index)
+	}
+
+	return report
+}
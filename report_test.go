@@ -0,0 +1,81 @@
+package main
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestBuildReport(t *testing.T) {
+	tests := []struct {
+		name        string
+		failing     []string
+		wantNodes   []string
+		wantIndices int
+		wantShards  bool // Whether the shard counts are known
+		wantUsage   bool // Whether the nodes stats metrics are known
+	}{
+		{
+			name:        "complete",
+			wantNodes:   []string{"es-hot-1", "es-master-1", "es-warm-1"},
+			wantIndices: 8,
+			wantShards:  true,
+			wantUsage:   true,
+		},
+		{
+			name:        "nodes stats failed",
+			failing:     []string{endpointNodesStats},
+			wantIndices: 8,
+			wantShards:  true,
+		},
+		{
+			name:      "indices and health failed",
+			failing:   []string{endpointIndices, endpointClusterHealth},
+			wantNodes: []string{"es-hot-1", "es-master-1", "es-warm-1"},
+			wantUsage: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, snap := collectFake(t, tt.failing...)
+			activities := map[string]*IndexActivity{"orders-2026.10": {InitialDocsCount: 18234455 - 1000}}
+			report := buildReport(snap, time.Minute, activities)
+
+			var nodes []string
+			for _, node := range report.Nodes {
+				nodes = append(nodes, node.Name)
+			}
+			if !slices.Equal(nodes, tt.wantNodes) {
+				t.Errorf("nodes = %v, want %v", nodes, tt.wantNodes)
+			}
+			if len(report.Indices) != tt.wantIndices {
+				t.Errorf("%d indices, want %d", len(report.Indices), tt.wantIndices)
+			}
+			for _, index := range report.Indices {
+				if index.Name == "orders-2026.10" && index.IngestedDocs != 1000 {
+					t.Errorf("ingested %d documents into orders-2026.10, want 1000 since the baseline", index.IngestedDocs)
+				}
+			}
+			if (report.Shards != nil) != tt.wantShards {
+				t.Errorf("shards = %+v, want them known: %v", report.Shards, tt.wantShards)
+			}
+			if (report.Metrics.HeapPercent != nil) != tt.wantUsage || (report.Metrics.DiskPercent != nil) != tt.wantUsage {
+				t.Errorf("heap and disk usage known: %v, want %v", report.Metrics.HeapPercent != nil, tt.wantUsage)
+			}
+			for _, path := range tt.failing {
+				if report.Errors[path] == "" {
+					t.Errorf("no error reported for %s", path)
+				}
+			}
+
+			// The cluster stats are in every case
+			if report.Cluster.Name != "prod-logging" || report.Cluster.NodesTotal != 3 {
+				t.Errorf("cluster = %+v, want prod-logging with 3 nodes", report.Cluster)
+			}
+			if want := []LaggingNode{{Name: "es-warm-1", Version: "8.14.3"}}; !slices.Equal(report.Cluster.LaggingNodes, want) {
+				t.Errorf("lagging nodes = %v, want %v", report.Cluster.LaggingNodes, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	historySize    = 60 // Samples kept per metric, five minutes at the default refresh
+	sparklineWidth = 16 // Characters per sparkline in the metrics panel
+)
+
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// Metrics that get a sparkline, keyed the same way as the metrics panel rows
+const (
+	metricCPU       = "CPU"
+	metricMemory    = "Memory"
+	metricHeap      = "Heap"
+	metricDisk      = "Disk"
+	metricNetworkTX = "Network TX"
+	metricNetworkRX = "Network RX"
+	metricHTTP      = "HTTP Connections"
+	metricQueryRate = "Query Rate"
+	metricIndexRate = "Index Rate"
+)
+
+// percentMetrics are drawn on a fixed 0-100 scale, so a flat line at 95% heap
+// still looks alarming. Everything else is scaled to its own maximum.
+var percentMetrics = map[string]bool{
+	metricCPU:    true,
+	metricMemory: true,
+	metricHeap:   true,
+	metricDisk:   true,
+}
+
+// History keeps the recent values of every sparkline metric
+type History struct {
+	series map[string]*ring[float64]
+}
+
+func NewHistory() *History {
+	return &History{series: make(map[string]*ring[float64])}
+}
+
+func (h *History) Add(metric string, value float64) {
+	r, ok := h.series[metric]
+	if !ok {
+		r = newRing[float64](historySize)
+		h.series[metric] = r
+	}
+	r.Add(value)
+}
+
+// Copy returns the values of every metric, oldest first, detached from the rings
+func (h *History) Copy() map[string][]float64 {
+	values := make(map[string][]float64, len(h.series))
+	for metric, r := range h.series {
+		values[metric] = r.Slice()
+	}
+	return values
+}
+
+// observeSnapshot records the current value of every metric whose source
+// endpoints answered. Rates are taken over window, the shortest one computed,
+// so the line follows what happened between polls.
+func (h *History) observeSnapshot(snap *Snapshot, rates Rates) {
+	if !snap.Failed(endpointClusterStats) {
+		h.Add(metricCPU, float64(snap.ClusterStats.Process.CPU.Percent))
+	}
+
+	if snap.Failed(endpointNodesStats) {
+		return
+	}
+
+	var heapUsed, heapMax, memUsed, memTotal int64
+	for _, node := range snap.NodesStats.Nodes {
+		heapUsed += node.JVM.Memory.HeapUsedInBytes
+		heapMax += node.JVM.Memory.HeapMaxInBytes
+		memUsed += node.OS.Memory.UsedInBytes
+		memTotal += node.OS.Memory.TotalInBytes
+	}
+
+	h.Add(metricMemory, percent(memUsed, memTotal))
+	h.Add(metricHeap, percent(heapUsed, heapMax))
+	h.Add(metricDisk, percent(getTotalSize(snap.NodesStats), getTotalDiskSpace(snap.NodesStats)))
+	h.Add(metricNetworkTX, rates.NetworkTX)
+	h.Add(metricNetworkRX, rates.NetworkRX)
+	h.Add(metricHTTP, float64(getTotalHTTPConnections(snap.NodesStats)))
+	h.Add(metricQueryRate, rates.Query)
+	h.Add(metricIndexRate, rates.Index)
+}
+
+func percent(used, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(used) / float64(total) * 100
+}
+
+// sparkline draws values as a line of block characters, averaging them into
+// width buckets when there are more values than characters
+func sparkline(values []float64, width int, fixedMax float64) string {
+	if len(values) == 0 {
+		return strings.Repeat(" ", width)
+	}
+
+	buckets := values
+	if len(values) > width {
+		buckets = make([]float64, width)
+		for i := range buckets {
+			from := i * len(values) / width
+			to := (i + 1) * len(values) / width
+			sum := 0.0
+			for _, v := range values[from:to] {
+				sum += v
+			}
+			buckets[i] = sum / float64(to-from)
+		}
+	}
+
+	max := fixedMax
+	if max == 0 {
+		for _, v := range buckets {
+			if v > max {
+				max = v
+			}
+		}
+	}
+
+	var b strings.Builder
+	for _, v := range buckets {
+		level := 0
+		if max > 0 {
+			level = int(v / max * float64(len(sparkBlocks)-1))
+		}
+		if level < 0 {
+			level = 0
+		}
+		if level >= len(sparkBlocks) {
+			level = len(sparkBlocks) - 1
+		}
+		b.WriteRune(sparkBlocks[level])
+	}
+
+	// Right align so the newest value always sits next to the trend arrow
+	return strings.Repeat(" ", width-len(buckets)) + b.String()
+}
+
+// trendArrow compares the newest value with the average of the history
+func trendArrow(values []float64) string {
+	if len(values) < 2 {
+		return " "
+	}
+
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	avg := sum / float64(len(values))
+	last := values[len(values)-1]
+
+	// Ignore jitter of a few percent around the average
+	margin := avg * 0.05
+	switch {
+	case last > avg+margin:
+		return "↑"
+	case last < avg-margin:
+		return "↓"
+	default:
+		return "→"
+	}
+}
+
+// formatSparkline renders the sparkline and trend arrow of a metric, colored
+// by its latest value for percentages
+func formatSparkline(metric string, values []float64) string {
+	color := "#8be9fd"
+	fixedMax := 0.0
+	if percentMetrics[metric] {
+		fixedMax = 100
+		if len(values) > 0 {
+			color = getPercentageColor(values[len(values)-1])
+		}
+	}
+	return fmt.Sprintf("[%s]%s [white]%s", color, sparkline(values, sparklineWidth, fixedMax), trendArrow(values))
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"sort"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// tableColumn is one column of a table of rows of type R
+type tableColumn[R any] struct {
+	title string
+	align int               // tview.AlignLeft or tview.AlignRight
+	text  func(R) string    // Content of the cell, with color tags
+	less  func(a, b R) bool // Ascending order, nil if the column cannot be sorted by
+}
+
+// tableSort is the order of a table. It lives in the App rather than in the
+// table, so it survives every refresh rebuilding the rows.
+type tableSort struct {
+	column int
+	desc   bool
+}
+
+// stepSort moves the sort to the next sortable column in direction dir
+// (+1/-1), wrapping around at both ends
+func stepSort[R any](s *tableSort, columns []tableColumn[R], dir int) {
+	for i := 1; i <= len(columns); i++ {
+		c := ((s.column+dir*i)%len(columns) + len(columns)) % len(columns)
+		if columns[c].less != nil {
+			s.column, s.desc = c, false
+			return
+		}
+	}
+}
+
+// sortBy sorts by column, reversing the order when it already is the sort
+// column, as clicking a column header twice does
+func (s *tableSort) sortBy(column int) {
+	if s.column == column {
+		s.desc = !s.desc
+		return
+	}
+	s.column, s.desc = column, false
+}
+
+// newTable builds a table whose cursor only shows while it has the focus
+func newTable(theme Theme) *tview.Table {
+	table := tview.NewTable().
+		SetFixed(1, 0).
+		SetSelectable(false, false).
+		SetSeparator(tview.Borders.Vertical).
+		SetBordersColor(theme.Border).
+		SetSelectedStyle(theme.cursorStyle())
+	table.SetFocusFunc(func() { table.SetSelectable(true, false) })
+	table.SetBlurFunc(func() { table.SetSelectable(false, false) })
+	return table
+}
+
+// fillTable replaces the content of table with a header and rows sorted by
+// s, padding every cell with a space on both sides. The cursor stays on the
+// row with the same key, as rows move around between refreshes. Clicking a
+// sortable header sorts by it and calls onSort to redraw.
+func fillTable[R any](table *tview.Table, columns []tableColumn[R], rows []R, key func(R) string, s *tableSort, onSort func()) {
+	selected := selectedKey(table)
+
+	if s.column >= 0 && s.column < len(columns) && columns[s.column].less != nil {
+		less := columns[s.column].less
+		sort.SliceStable(rows, func(i, j int) bool {
+			if s.desc {
+				return less(rows[j], rows[i])
+			}
+			return less(rows[i], rows[j])
+		})
+	}
+
+	table.Clear()
+	for c, column := range columns {
+		title := column.title
+		if c == s.column {
+			if s.desc {
+				title += " ▼"
+			} else {
+				title += " ▲"
+			}
+		}
+
+		cell := tview.NewTableCell(" [::b][#00ffff]" + title + " ").
+			SetAlign(column.align).
+			SetSelectable(false)
+		if column.less != nil {
+			c := c
+			cell.SetClickedFunc(func() bool {
+				s.sortBy(c)
+				onSort()
+				return true
+			})
+		}
+		table.SetCell(0, c, cell)
+	}
+
+	cursor := 1
+	for r, row := range rows {
+		for c, column := range columns {
+			cell := tview.NewTableCell(" " + column.text(row) + " ").SetAlign(column.align)
+			if c == 0 {
+				cell.SetReference(key(row))
+			}
+			table.SetCell(r+1, c, cell)
+		}
+		if key(row) == selected {
+			cursor = r + 1
+		}
+	}
+	if len(rows) > 0 {
+		table.Select(cursor, 0)
+	}
+}
+
+// selectedKey returns the key of the row under the cursor, "" if none
+func selectedKey(table *tview.Table) string {
+	row, _ := table.GetSelection()
+	if row < 1 || row >= table.GetRowCount() {
+		return ""
+	}
+	ref, _ := table.GetCell(row, 0).GetReference().(string)
+	return ref
+}
+
+// recolorTable rewrites the color tags of every cell of an already filled table
+func (t Theme) recolorTable(tables ...*tview.Table) {
+	if t.recolor == nil {
+		return
+	}
+	for _, table := range tables {
+		for r := 0; r < table.GetRowCount(); r++ {
+			for c := 0; c < table.GetColumnCount(); c++ {
+				if cell := table.GetCell(r, c); cell != nil {
+					cell.SetText(t.recolor(cell.Text))
+				}
+			}
+		}
+	}
+}
+
+// cursorStyle is the style of the row under the cursor of a table: text and
+// background swapped, or plain reverse video when the theme has no colors
+func (t Theme) cursorStyle() tcell.Style {
+	if t.Text == tcell.ColorDefault {
+		return tcell.StyleDefault.Reverse(true)
+	}
+	return tcell.StyleDefault.Foreground(t.Background).Background(t.Text)
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/rivo/tview"
+)
+
+func TestFillTable(t *testing.T) {
+	type row struct {
+		name string
+		docs int
+	}
+	columns := []tableColumn[row]{
+		{title: "Name", text: func(r row) string { return r.name }, less: func(a, b row) bool { return a.name < b.name }},
+		{title: "Docs", text: func(r row) string { return formatNumber(r.docs) }, less: func(a, b row) bool { return a.docs < b.docs }},
+	}
+	key := func(r row) string { return r.name }
+	names := func(table *tview.Table) []string {
+		var names []string
+		for r := 1; r < table.GetRowCount(); r++ {
+			names = append(names, table.GetCell(r, 0).GetReference().(string))
+		}
+		return names
+	}
+
+	table := newTable(themes["default"])
+	s := tableSort{column: 1, desc: true}
+	rows := []row{{"a", 10}, {"b", 30}, {"c", 20}}
+	fillTable(table, columns, rows, key, &s, func() {})
+	if got, want := names(table), []string{"b", "c", "a"}; !slices.Equal(got, want) {
+		t.Fatalf("sorted by docs descending = %v, want %v", got, want)
+	}
+	if got := table.GetCell(0, 1).Text; got != " [::b][#00ffff]Docs ▼ " {
+		t.Errorf("sort column header = %q, want the descending arrow", got)
+	}
+
+	// The cursor follows its row when a refresh moves it
+	table.Select(2, 0)
+	rows = []row{{"a", 10}, {"b", 30}, {"c", 40}}
+	fillTable(table, columns, rows, key, &s, func() {})
+	if got := selectedKey(table); got != "c" {
+		t.Errorf("cursor on %q after the refresh, want it to stay on c", got)
+	}
+
+	// Clicking the header of the sort column reverses it
+	sorted := false
+	fillTable(table, columns, rows, key, &s, func() { sorted = true })
+	if !table.GetCell(0, 1).Clicked() || !sorted {
+		t.Fatal("clicking a sortable header must sort and redraw without selecting it")
+	}
+	if s != (tableSort{column: 1}) {
+		t.Errorf("sort after clicking the sort column = %+v, want it ascending", s)
+	}
+
+	stepSort(&s, columns, 1)
+	if s != (tableSort{column: 0}) {
+		t.Errorf("next sort column = %+v, want it to wrap around to the first", s)
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// Theme recolors the dashboard. Panels are rendered with the default dark
+// palette and a theme rewrites their color tags afterwards, so the render
+// code never has to know which theme is active.
+type Theme struct {
+	Background tcell.Color
+	Text       tcell.Color
+	Border     tcell.Color
+	recolor    func(text string) string
+}
+
+var colorTag = regexp.MustCompile(`\[(#[0-9a-fA-F]{6}|white|red|green|yellow)\]`)
+
+var themes = map[string]Theme{
+	"default": {
+		Background: tcell.ColorBlack,
+		Text:       tcell.ColorWhite,
+		Border:     tcell.ColorWhite,
+	},
+	// light keeps the meaning of every color but darkens them so they stay
+	// readable on a white terminal
+	"light": {
+		Background: tcell.ColorWhite,
+		Text:       tcell.ColorBlack,
+		Border:     tcell.ColorGray,
+		recolor: strings.NewReplacer(
+			"[white]", "[black]",
+			"[#00ffff]", "[#005f87]",
+			"[#8be9fd]", "[#005f87]",
+			"[#444444]", "[#a8a8a8]",
+			"[#666666]", "[#808080]",
+			"[#5555ff]", "[#0000af]",
+			"[#bd93f9]", "[#5f00af]",
+			"[#ff99cc]", "[#af005f]",
+			"[#ffff00]", "[#af8700]",
+			"[yellow]", "[#af8700]",
+			"[#50fa7b]", "[#008700]",
+			"[green]", "[#008700]",
+			"[#ff5555]", "[#d70000]",
+			"[red]", "[#d70000]",
+		).Replace,
+	},
+	// mono drops every color, for terminals and recordings without them
+	"mono": {
+		Background: tcell.ColorDefault,
+		Text:       tcell.ColorDefault,
+		Border:     tcell.ColorDefault,
+		recolor: func(text string) string {
+			return colorTag.ReplaceAllString(text, "[-]")
+		},
+	},
+}
+
+// lookupTheme returns the named theme
+func lookupTheme(name string) (Theme, error) {
+	theme, ok := themes[name]
+	if !ok {
+		names := make([]string, 0, len(themes))
+		for name := range themes {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return Theme{}, fmt.Errorf("unknown theme %q, use one of %s", name, strings.Join(names, ", "))
+	}
+	return theme, nil
+}
+
+// applyStyles sets the tview defaults. It must run before any widget is
+// created, since widgets copy the defaults when they are built.
+func (t Theme) applyStyles() {
+	tview.Styles.PrimitiveBackgroundColor = t.Background
+	tview.Styles.PrimaryTextColor = t.Text
+	tview.Styles.BorderColor = t.Border
+	tview.Styles.GraphicsColor = t.Border
+}
+
+// recolorPanels rewrites the color tags of already rendered panels
+func (t Theme) recolorPanels(panels ...*tview.TextView) {
+	if t.recolor == nil {
+		return
+	}
+	for _, panel := range panels {
+		panel.SetText(t.recolor(panel.GetText(false)))
+	}
+}
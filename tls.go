@@ -0,0 +1,93 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// TLSOptions describes how to verify the cluster and, for mutual TLS, how to
+// identify ourselves to it
+type TLSOptions struct {
+	CAFile     string // PEM bundle of CAs to trust instead of the system pool
+	CertFile   string // Client certificate for mutual TLS
+	KeyFile    string // Key of the client certificate
+	ServerName string // Name to verify the server certificate against
+	MinVersion string // Lowest TLS version to accept: 1.0, 1.1, 1.2 or 1.3
+	Insecure   bool   // Skip certificate verification entirely
+}
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// Config builds the tls.Config for these options
+func (o TLSOptions) Config() (*tls.Config, error) {
+	minVersion, ok := tlsVersions[o.MinVersion]
+	if !ok {
+		return nil, fmt.Errorf("unsupported TLS version %q, use one of 1.0, 1.1, 1.2 or 1.3", o.MinVersion)
+	}
+
+	cfg := &tls.Config{
+		MinVersion:         minVersion,
+		ServerName:         o.ServerName,
+		InsecureSkipVerify: o.Insecure,
+	}
+
+	if o.CAFile != "" {
+		pem, err := os.ReadFile(o.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading CA bundle: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA bundle %s", o.CAFile)
+		}
+		cfg.RootCAs = pool
+	}
+
+	if (o.CertFile == "") != (o.KeyFile == "") {
+		return nil, errors.New("client certificate and key must be given together")
+	}
+	if o.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(o.CertFile, o.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("loading client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return cfg, nil
+}
+
+// describeError turns TLS and certificate failures into a message saying
+// what went wrong and which flag fixes it. Other errors are returned as is.
+func describeError(err error) string {
+	var (
+		unknownAuthority x509.UnknownAuthorityError
+		hostname         x509.HostnameError
+		invalid          x509.CertificateInvalidError
+		recordHeader     tls.RecordHeaderError
+	)
+
+	switch {
+	case errors.As(err, &unknownAuthority):
+		return "TLS: server certificate is signed by an unknown authority, pass its CA with -cacert (or -insecure to skip verification)"
+	case errors.As(err, &hostname):
+		return fmt.Sprintf("TLS: server certificate is not valid for %s, check the host or set -server-name", hostname.Host)
+	case errors.As(err, &invalid):
+		return fmt.Sprintf("TLS: server certificate is invalid: %v", invalid)
+	case errors.As(err, &recordHeader):
+		return "TLS: server did not answer with TLS, try http:// instead of https://"
+	case strings.Contains(err.Error(), "remote error: tls:"):
+		return fmt.Sprintf("TLS: server rejected the handshake, check -cert/-key: %v", err)
+	default:
+		return err.Error()
+	}
+}
@@ -0,0 +1,352 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	versionCheckInterval = time.Hour       // How long a looked up release is trusted
+	versionRetryInterval = 5 * time.Minute // Pause after a failed lookup
+)
+
+// releaseEntry is the latest release found at one feed
+type releaseEntry struct {
+	Version   string    `json:"version"`
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+// versionChecker looks up the latest release of the cluster's distribution in
+// the background and caches it on disk, so the check neither blocks a poll
+// nor starts from scratch on every run. It is shared by all clusters.
+type versionChecker struct {
+	mu        sync.Mutex
+	cachePath string
+	releases  map[string]releaseEntry // By feed URL
+	attempted map[string]time.Time    // Last lookup started, by feed URL
+}
+
+// newVersionChecker loads the releases cached at cachePath, if any. An empty
+// cachePath keeps the cache in memory only.
+func newVersionChecker(cachePath string) *versionChecker {
+	v := &versionChecker{
+		cachePath: cachePath,
+		releases:  make(map[string]releaseEntry),
+		attempted: make(map[string]time.Time),
+	}
+	if cachePath != "" {
+		if data, err := os.ReadFile(cachePath); err == nil {
+			json.Unmarshal(data, &v.releases)
+		}
+	}
+	return v
+}
+
+// defaultVersionCachePath is the cache file in the user's cache directory
+// (~/.cache/elastop on Linux)
+func defaultVersionCachePath() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "elastop", "latest-version.json")
+}
+
+// Latest returns the latest release published at feed, or "" while it is
+// unknown. An outdated or missing release is looked up in the background and
+// shows up in a later call; until then the outdated one is returned. The
+// lookup goes through client.
+func (v *versionChecker) Latest(feed string, client *http.Client) string {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	entry, ok := v.releases[feed]
+	if (!ok || time.Since(entry.FetchedAt) >= versionCheckInterval) &&
+		time.Since(v.attempted[feed]) >= versionRetryInterval {
+		v.attempted[feed] = time.Now()
+		go v.lookup(feed, client)
+	}
+	return entry.Version
+}
+
+func (v *versionChecker) lookup(feed string, client *http.Client) {
+	version, err := fetchLatestRelease(client, feed)
+	if err != nil {
+		return
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.releases[feed] = releaseEntry{Version: version, FetchedAt: time.Now()}
+	v.save()
+}
+
+// save writes the cache file. Failing to is not worth bothering anyone with,
+// the next run simply looks the release up again.
+func (v *versionChecker) save() {
+	if v.cachePath == "" {
+		return
+	}
+	data, err := json.Marshal(v.releases)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(v.cachePath), 0o755); err != nil {
+		return
+	}
+	// Write to a temporary file first so a concurrent run never reads half a file
+	tmp := v.cachePath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return
+	}
+	os.Rename(tmp, v.cachePath)
+}
+
+// fetchLatestRelease reads the latest version from a release feed: either a
+// GitHub latest release document, or any URL answering with just the version
+func fetchLatestRelease(client *http.Client, feed string) (string, error) {
+	resp, err := client.Get(feed)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("release feed answered with status %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", err
+	}
+
+	version := strings.TrimSpace(string(body))
+	if strings.HasPrefix(version, "{") {
+		var release GitHubRelease
+		if err := json.Unmarshal(body, &release); err != nil {
+			return "", err
+		}
+		version = release.TagName
+	}
+
+	version = strings.TrimPrefix(version, "v")
+	if version == "" || strings.ContainsAny(version, " \n<") {
+		return "", errors.New("release feed did not answer with a version")
+	}
+	return version, nil
+}
+
+// versionColor colors a node version by whether it is the latest release.
+// With the latest release unknown it stays neutral rather than claiming the
+// node is up to date.
+func versionColor(current, latest string) string {
+	switch {
+	case latest == "":
+		return "white"
+	case compareVersions(current, latest):
+		return "green"
+	default:
+		return "yellow"
+	}
+}
+
+// describeLatest renders the latest release for the header
+func describeLatest(latest string, checked bool) string {
+	switch {
+	case !checked:
+		return "[#666666]not checked[white]"
+	case latest == "":
+		return "[#666666]unknown[white]"
+	default:
+		return latest
+	}
+}
+
+// semver is a parsed version such as 8.15.0, 8.15.0-SNAPSHOT or 8.x.
+// Elasticsearch versions follow semantic versioning, so precedence is the
+// one of semver.org: pre-releases sort before their release and build
+// metadata is ignored.
+type semver struct {
+	parts []int    // Major, minor, patch; shorter for wildcards like 8.x
+	pre   []string // Dot separated pre-release identifiers, e.g. ["SNAPSHOT"]
+}
+
+// parseSemver parses a version, allowing a leading v, missing minor or patch
+// numbers and x or * as a wildcard for the remaining ones
+func parseSemver(version string) (semver, bool) {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	version, _, _ = strings.Cut(version, "+")
+	core, pre, hasPre := strings.Cut(version, "-")
+
+	var v semver
+	for i, part := range strings.Split(core, ".") {
+		if part == "x" || part == "X" || part == "*" {
+			break
+		}
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 || i >= 3 {
+			return semver{}, false
+		}
+		v.parts = append(v.parts, n)
+	}
+	if len(v.parts) == 0 {
+		return semver{}, false
+	}
+
+	if hasPre {
+		if pre == "" {
+			return semver{}, false
+		}
+		v.pre = strings.Split(pre, ".")
+	}
+	return v, true
+}
+
+// compare returns -1, 0 or 1 as v is older than, the same as or newer than
+// other. Parts missing from either side, as in 8.x, match anything.
+func (v semver) compare(other semver) int {
+	for i := 0; i < len(v.parts) && i < len(other.parts); i++ {
+		if c := compareInts(v.parts[i], other.parts[i]); c != 0 {
+			return c
+		}
+	}
+	if len(v.parts) < 3 || len(other.parts) < 3 {
+		return 0
+	}
+
+	// A release is newer than any of its pre-releases
+	switch {
+	case len(v.pre) == 0 && len(other.pre) == 0:
+		return 0
+	case len(v.pre) == 0:
+		return 1
+	case len(other.pre) == 0:
+		return -1
+	}
+
+	for i := 0; i < len(v.pre) && i < len(other.pre); i++ {
+		a, b := v.pre[i], other.pre[i]
+		an, aErr := strconv.Atoi(a)
+		bn, bErr := strconv.Atoi(b)
+		switch {
+		case aErr == nil && bErr == nil:
+			if c := compareInts(an, bn); c != 0 {
+				return c
+			}
+		case aErr == nil:
+			return -1 // Numeric identifiers sort before alphanumeric ones
+		case bErr == nil:
+			return 1
+		case a != b:
+			if a < b {
+				return -1
+			}
+			return 1
+		}
+	}
+	return compareInts(len(v.pre), len(other.pre))
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// compareVersions reports whether current is at least latest. Versions that
+// cannot be parsed only count as up to date when they are identical.
+func compareVersions(current, latest string) bool {
+	if latest == "" {
+		return true
+	}
+
+	cur, ok1 := parseSemver(current)
+	lat, ok2 := parseSemver(latest)
+	if !ok1 || !ok2 {
+		return strings.TrimPrefix(current, "v") == strings.TrimPrefix(latest, "v")
+	}
+	return cur.compare(lat) >= 0
+}
+
+// compareVersionOrder orders versions for sorting, returning -1, 0 or 1.
+// Unlike compare it is a total order: versions that match through a
+// wildcard put the shorter one first, equal ones fall back to their text
+// and versions that cannot be parsed come last.
+func compareVersionOrder(a, b string) int {
+	va, okA := parseSemver(a)
+	vb, okB := parseSemver(b)
+	switch {
+	case okA && okB:
+		if c := va.compare(vb); c != 0 {
+			return c
+		}
+		if c := compareInts(len(va.parts), len(vb.parts)); c != 0 {
+			return c
+		}
+	case okA:
+		return -1
+	case okB:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+// laggingNode is a node running an older version than the newest in the cluster
+type laggingNode struct {
+	name    string
+	version string
+}
+
+// mixedVersions finds the newest version in the cluster and the nodes that
+// run an older one, as during a rolling upgrade. lagging is empty when every
+// node runs the same version.
+func mixedVersions(info NodesInfo) (newest string, lagging []laggingNode) {
+	var newestVersion semver
+	for _, node := range info.Nodes {
+		v, ok := parseSemver(node.Version)
+		if !ok {
+			continue
+		}
+		if newest == "" || v.compare(newestVersion) > 0 {
+			newest, newestVersion = node.Version, v
+		}
+	}
+	// Without a single readable version there is nothing to lag behind
+	if newest == "" {
+		return "", nil
+	}
+
+	for _, node := range info.Nodes {
+		if node.Version == newest {
+			continue
+		}
+		if v, ok := parseSemver(node.Version); !ok || v.compare(newestVersion) < 0 {
+			lagging = append(lagging, laggingNode{name: node.Name, version: node.Version})
+		}
+	}
+
+	// Oldest first, as those are the nodes furthest from done
+	sort.Slice(lagging, func(i, j int) bool {
+		vi, _ := parseSemver(lagging[i].version)
+		vj, _ := parseSemver(lagging[j].version)
+		if c := vi.compare(vj); c != 0 {
+			return c < 0
+		}
+		return lagging[i].name < lagging[j].name
+	})
+	return newest, lagging
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		current, latest string
+		want            bool
+	}{
+		{"8.15.0", "8.15.0", true},
+		{"8.15.1", "8.15.0", true},
+		{"10.0.0", "9.9.9", true},
+		{"8.15.0-SNAPSHOT", "8.15.0", false},
+		{"8.15.0", "8.15.0-SNAPSHOT", true},
+		{"8.15.0-alpha.2", "8.15.0-alpha.10", false},
+		{"8.15.0+build.7", "8.15.0", true},
+		{"v8.15.0", "8.15.0", true},
+		{"7.17.24", "8.x", false},
+		{"8.15.0", "8.x", true},
+		{"garbage", "8.15.0", false},
+		{"8.15.0", "", true},
+	}
+	for _, tt := range tests {
+		if got := compareVersions(tt.current, tt.latest); got != tt.want {
+			t.Errorf("compareVersions(%q, %q) = %v, want %v", tt.current, tt.latest, got, tt.want)
+		}
+	}
+}
+
+func TestMixedVersions(t *testing.T) {
+	tests := []struct {
+		name        string
+		versions    map[string]string // Node name to version
+		wantNewest  string
+		wantLagging []laggingNode
+	}{
+		{
+			name:       "same version",
+			versions:   map[string]string{"es-1": "8.15.0", "es-2": "8.15.0"},
+			wantNewest: "8.15.0",
+		},
+		{
+			name:        "snapshot",
+			versions:    map[string]string{"es-1": "8.15.0", "es-2": "8.15.0-SNAPSHOT"},
+			wantNewest:  "8.15.0",
+			wantLagging: []laggingNode{{"es-2", "8.15.0-SNAPSHOT"}},
+		},
+		{
+			name:        "rolling upgrade",
+			versions:    map[string]string{"es-1": "8.15.0", "es-2": "7.17.24", "es-3": "7.17.9", "es-4": "8.15.0"},
+			wantNewest:  "8.15.0",
+			wantLagging: []laggingNode{{"es-3", "7.17.9"}, {"es-2", "7.17.24"}},
+		},
+		{
+			name:        "garbage next to a version",
+			versions:    map[string]string{"es-1": "8.15.0", "es-2": "garbage"},
+			wantNewest:  "8.15.0",
+			wantLagging: []laggingNode{{"es-2", "garbage"}},
+		},
+		{
+			name:     "only garbage",
+			versions: map[string]string{"es-1": "garbage", "es-2": "", "es-3": "8.15.0.1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := NodesInfo{Nodes: make(map[string]NodeInfo)}
+			for name, version := range tt.versions {
+				info.Nodes["id-"+name] = NodeInfo{Name: name, Version: version}
+			}
+			newest, lagging := mixedVersions(info)
+			if newest != tt.wantNewest || !slices.Equal(lagging, tt.wantLagging) {
+				t.Errorf("mixedVersions() = %q, %v, want %q, %v", newest, lagging, tt.wantNewest, tt.wantLagging)
+			}
+		})
+	}
+}
+
+func TestCompareVersionOrder(t *testing.T) {
+	want := []string{"7.17.9", "7.17.24", "8.x", "8.15.0-SNAPSHOT", "8.15.0", "8.15.0+build.1", "8.15.0+build.2", "10.0.0", "", "garbage"}
+	for i, a := range want {
+		for j, b := range want {
+			if got := compareVersionOrder(a, b); got != compareInts(i, j) {
+				t.Errorf("compareVersionOrder(%q, %q) = %d, want %d", a, b, got, compareInts(i, j))
+			}
+		}
+	}
+}